@@ -0,0 +1,117 @@
+package main
+
+import (
+	"blueprint"
+)
+
+// ensembleSearchSteps is the number of candidate values tried for each weight during coordinate ascent
+const ensembleSearchSteps = 20
+
+// ensembleMaxPasses bounds the number of coordinate ascent sweeps over all models
+const ensembleMaxPasses = 10
+
+// EnsemblePredict combines the outputs of several models as a weighted average.
+// Passing nil weights averages the models equally. It returns nil when weights does not have one entry per model.
+func EnsemblePredict(models []Predictor, weights []float64, inputVariables map[string]interface{}) map[string]float64 {
+	if weights != nil && len(weights) != len(models) {
+		return nil
+	}
+
+	outputs := make([]map[string]float64, len(models))
+	for i, model := range models {
+		outputs[i] = model.Feedforward(inputVariables)
+	}
+	return combineOutputs(outputs, weights)
+}
+
+// combineOutputs averages output maps using the given weights, or equally when weights is nil
+func combineOutputs(outputs []map[string]float64, weights []float64) map[string]float64 {
+	combined := make(map[string]float64)
+	for i, output := range outputs {
+		weight := 1.0 / float64(len(outputs))
+		if weights != nil {
+			weight = weights[i]
+		}
+		for key, value := range output {
+			combined[key] += weight * value
+		}
+	}
+	return combined
+}
+
+// LearnEnsembleWeights fits non-negative weights summing to 1 that maximize the ensemble's accuracy
+// on a validation set. It uses coordinate ascent: each model's weight is tried at evenly spaced values
+// while the other weights are rescaled to keep the total at 1. Ties in accuracy are broken by the mean
+// probability given to the true class, which steers weight towards the more confident, stronger models.
+func LearnEnsembleWeights(models []Predictor, val []blueprint.TrainingSession) []float64 {
+	if len(models) == 0 {
+		return nil
+	}
+
+	weights := make([]float64, len(models))
+	for i := range weights {
+		weights[i] = 1.0 / float64(len(models))
+	}
+	if len(models) == 1 || len(val) == 0 {
+		return weights
+	}
+
+	// Run every model over the validation set once and reuse the outputs while searching
+	outputs := make([][]map[string]float64, len(val))
+	expected := make([]string, len(val))
+	for s, session := range val {
+		outputs[s] = make([]map[string]float64, len(models))
+		for m, model := range models {
+			outputs[s][m] = model.Feedforward(session.InputVariables)
+		}
+		expected[s] = expectedClass(session)
+	}
+
+	score := func(candidate []float64) (accuracy, confidence float64) {
+		for s := range val {
+			combined := combineOutputs(outputs[s], candidate)
+			if PredictClass(combined) == expected[s] {
+				accuracy++
+			}
+			confidence += combined[expected[s]]
+		}
+		return accuracy / float64(len(val)), confidence / float64(len(val))
+	}
+
+	bestAccuracy, bestConfidence := score(weights)
+	for pass := 0; pass < ensembleMaxPasses; pass++ {
+		improved := false
+		for m := range models {
+			for step := 0; step <= ensembleSearchSteps; step++ {
+				candidate := reweight(weights, m, float64(step)/ensembleSearchSteps)
+				accuracy, confidence := score(candidate)
+				if accuracy > bestAccuracy || (accuracy == bestAccuracy && confidence > bestConfidence) {
+					weights, bestAccuracy, bestConfidence = candidate, accuracy, confidence
+					improved = true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return weights
+}
+
+// reweight returns a copy of weights with model index set to value and the others rescaled to sum to 1-value
+func reweight(weights []float64, index int, value float64) []float64 {
+	candidate := make([]float64, len(weights))
+	remaining := 1 - weights[index]
+	for i, weight := range weights {
+		switch {
+		case i == index:
+			candidate[i] = value
+		case remaining > 0:
+			candidate[i] = weight / remaining * (1 - value)
+		default:
+			// All weight sat on the chosen model, so spread the rest evenly
+			candidate[i] = (1 - value) / float64(len(weights)-1)
+		}
+	}
+	return candidate
+}
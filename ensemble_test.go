@@ -0,0 +1,55 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEnsemblePredictRejectsMismatchedWeights(t *testing.T) {
+	models := []Predictor{nil, nil}
+	input := map[string]interface{}{"input": []float64{1}}
+	if got := EnsemblePredict(models, []float64{1}, input); got != nil {
+		t.Fatalf("EnsemblePredict() = %v, want nil for mismatched weights", got)
+	}
+}
+
+func TestCombineOutputsWeightsModels(t *testing.T) {
+	outputs := []map[string]float64{
+		{"class_0": 1, "class_1": 0},
+		{"class_0": 0, "class_1": 1},
+	}
+
+	combined := combineOutputs(outputs, []float64{0.75, 0.25})
+	if math.Abs(combined["class_0"]-0.75) > 1e-12 || math.Abs(combined["class_1"]-0.25) > 1e-12 {
+		t.Fatalf("combineOutputs() = %v, want class_0 0.75 and class_1 0.25", combined)
+	}
+
+	averaged := combineOutputs(outputs, nil)
+	if averaged["class_0"] != 0.5 || averaged["class_1"] != 0.5 {
+		t.Fatalf("combineOutputs(nil weights) = %v, want an equal average", averaged)
+	}
+}
+
+func TestLearnEnsembleWeightsFavoursStrongModel(t *testing.T) {
+	labels := []int{0, 1, 1, 0, 1, 1}
+	val := labelledSessions(labels, 2)
+
+	// The strong model knows every label; the weak one always answers class_0 just as confidently
+	strong := &fakeModel{predict: func(input []float64) map[string]float64 {
+		if labels[int(input[0])] == 1 {
+			return map[string]float64{"class_0": 0.1, "class_1": 0.9}
+		}
+		return map[string]float64{"class_0": 0.9, "class_1": 0.1}
+	}}
+	weak := &fakeModel{predict: func(input []float64) map[string]float64 {
+		return map[string]float64{"class_0": 0.9, "class_1": 0.1}
+	}}
+
+	weights := LearnEnsembleWeights([]Predictor{weak, strong}, val)
+	if len(weights) != 2 || weights[1] <= weights[0] {
+		t.Fatalf("LearnEnsembleWeights() = %v, want the strong model (index 1) weighted higher", weights)
+	}
+	if math.Abs(weights[0]+weights[1]-1) > 1e-9 {
+		t.Fatalf("LearnEnsembleWeights() = %v, want weights summing to 1", weights)
+	}
+}
@@ -0,0 +1,853 @@
+package main
+
+import (
+	"blueprint"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fullAccuracy is the percentage at which a single-session evaluation counts as correct,
+// with a little slack for floating point rounding
+const fullAccuracy = 100 - 1e-9
+
+// Predictor is the part of a model the evaluation helpers run. *blueprint.Blueprint implements it,
+// and tests substitute models with known outputs.
+type Predictor interface {
+	Feedforward(inputVariables map[string]interface{}) map[string]float64
+}
+
+// Evaluator is a Predictor that can also score a dataset with the six metrics of EvaluateModelPerformance
+type Evaluator interface {
+	Predictor
+	EvaluateModelPerformance(sessions []blueprint.TrainingSession) (exactAccuracy, generousAccuracy, forgivenessAccuracy,
+		exactErrorCount, averageGenerousError, forgivenessErrorCount float64)
+}
+
+// classIndex extracts N from an output key of the form "class_N", returning -1 for any other key
+func classIndex(key string) int {
+	if !strings.HasPrefix(key, "class_") {
+		return -1
+	}
+	index, err := strconv.Atoi(strings.TrimPrefix(key, "class_"))
+	if err != nil {
+		return -1
+	}
+	return index
+}
+
+// classKeyLess orders class_N keys by index ahead of every other key, which are ordered lexicographically.
+// Keeping the two groups apart makes the order total, so mixed keys such as class_10 and class_1x cannot form a cycle.
+func classKeyLess(a, b string) bool {
+	indexA, indexB := classIndex(a), classIndex(b)
+	switch {
+	case indexA >= 0 && indexB >= 0:
+		return indexA < indexB
+	case indexA >= 0 || indexB >= 0:
+		return indexA >= 0
+	}
+	return a < b
+}
+
+// sortClassKeys sorts output keys in place so class_2 comes before class_10
+func sortClassKeys(keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		return classKeyLess(keys[i], keys[j])
+	})
+}
+
+// toFloat64 converts the numeric values stored in ExpectedOutput to float64
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// PredictClass returns the output key with the highest activation.
+// Ties are broken in favour of the lowest class index, so the winner never
+// depends on map iteration order and evaluation is reproducible between runs.
+func PredictClass(output map[string]float64) string {
+	keys := make([]string, 0, len(output))
+	for key := range output {
+		keys = append(keys, key)
+	}
+	sortClassKeys(keys)
+
+	best := ""
+	for _, key := range keys {
+		// Strict comparison keeps the earliest key on a tie
+		if best == "" || output[key] > output[best] {
+			best = key
+		}
+	}
+	return best
+}
+
+// expectedClass returns the class a session is labelled with (the argmax of its one-hot ExpectedOutput)
+func expectedClass(session blueprint.TrainingSession) string {
+	expected := make(map[string]float64, len(session.ExpectedOutput))
+	for key, value := range session.ExpectedOutput {
+		if v, ok := toFloat64(value); ok {
+			expected[key] = v
+		}
+	}
+	return PredictClass(expected)
+}
+
+// SetMetrics groups the six values returned by EvaluateModelPerformance for one dataset,
+// together with the dataset's chance level and how far the exact accuracy lies above it
+type SetMetrics struct {
+	ExactAccuracy         float64
+	GenerousAccuracy      float64
+	ForgivenessAccuracy   float64
+	ExactErrorCount       float64
+	AverageGenerousError  float64
+	ForgivenessErrorCount float64
+	ChanceLevel           float64 // Accuracy percentage expected from guessing by class frequency
+	AboveChance           float64 // Percentage points of exact accuracy above ChanceLevel
+}
+
+// PerformanceReport holds the metrics for both the training and testing sets
+type PerformanceReport struct {
+	Training SetMetrics
+	Testing  SetMetrics
+}
+
+// evaluateSet runs EvaluateModelPerformance on a dataset and captures the results in a SetMetrics
+func evaluateSet(model Evaluator, sessions []blueprint.TrainingSession) SetMetrics {
+	exactAccuracy, generousAccuracy, forgivenessAccuracy,
+		exactErrorCount, averageGenerousError, forgivenessErrorCount := model.EvaluateModelPerformance(sessions)
+
+	chanceLevel := ChanceLevel(sessions) * 100
+	return SetMetrics{
+		ExactAccuracy:         exactAccuracy,
+		GenerousAccuracy:      generousAccuracy,
+		ForgivenessAccuracy:   forgivenessAccuracy,
+		ExactErrorCount:       exactErrorCount,
+		AverageGenerousError:  averageGenerousError,
+		ForgivenessErrorCount: forgivenessErrorCount,
+		ChanceLevel:           chanceLevel,
+		AboveChance:           exactAccuracy - chanceLevel,
+	}
+}
+
+// FullReport evaluates the model on the training and testing sets and returns all metrics without printing
+func FullReport(model Evaluator, train, test []blueprint.TrainingSession) PerformanceReport {
+	return PerformanceReport{
+		Training: evaluateSet(model, train),
+		Testing:  evaluateSet(model, test),
+	}
+}
+
+// CompareModels runs both models over the sessions and returns the fraction of sessions where their
+// predicted classes agree and the mean absolute difference between their output values
+func CompareModels(a, b Predictor, sessions []blueprint.TrainingSession) (agreement float64, meanAbsDiff float64) {
+	if len(sessions) == 0 {
+		return 0, 0
+	}
+
+	agreements := 0
+	totalDiff := 0.0
+	comparedValues := 0
+	for _, session := range sessions {
+		outputA := a.Feedforward(session.InputVariables)
+		outputB := b.Feedforward(session.InputVariables)
+
+		if PredictClass(outputA) == PredictClass(outputB) {
+			agreements++
+		}
+
+		for key, valueA := range outputA {
+			// A key missing from the other model counts as an output of zero
+			totalDiff += math.Abs(valueA - outputB[key])
+			comparedValues++
+		}
+		for key, valueB := range outputB {
+			if _, exists := outputA[key]; !exists {
+				totalDiff += math.Abs(valueB)
+				comparedValues++
+			}
+		}
+	}
+
+	agreement = float64(agreements) / float64(len(sessions))
+	if comparedValues > 0 {
+		meanAbsDiff = totalDiff / float64(comparedValues)
+	}
+	return agreement, meanAbsDiff
+}
+
+// ReliabilityBin holds the statistics for one confidence bin of a reliability diagram
+type ReliabilityBin struct {
+	LowerBound     float64
+	UpperBound     float64
+	Count          int
+	MeanConfidence float64
+	Accuracy       float64
+}
+
+// CalibrationError computes the Expected Calibration Error by grouping predictions into equal-width
+// confidence bins and comparing each bin's mean confidence with its empirical accuracy.
+// The per-bin data is returned as well so a reliability diagram can be plotted.
+// Sessions whose confidence is not a finite number cannot be binned and are left out of the error.
+func CalibrationError(model Predictor, sessions []blueprint.TrainingSession, bins int) (float64, []ReliabilityBin) {
+	if bins <= 0 || len(sessions) == 0 {
+		return 0, nil
+	}
+
+	reliability := make([]ReliabilityBin, bins)
+	confidenceSums := make([]float64, bins)
+	correctCounts := make([]int, bins)
+	binned := 0
+	for i := range reliability {
+		reliability[i].LowerBound = float64(i) / float64(bins)
+		reliability[i].UpperBound = float64(i+1) / float64(bins)
+	}
+
+	for _, session := range sessions {
+		output := model.Feedforward(session.InputVariables)
+		predicted := PredictClass(output)
+		if math.IsNaN(output[predicted]) || math.IsInf(output[predicted], 0) {
+			continue
+		}
+		confidence := math.Min(math.Max(output[predicted], 0), 1)
+
+		binned++
+		bin := int(confidence * float64(bins))
+		if bin == bins {
+			bin = bins - 1
+		}
+		reliability[bin].Count++
+		confidenceSums[bin] += confidence
+		if predicted == expectedClass(session) {
+			correctCounts[bin]++
+		}
+	}
+
+	if binned == 0 {
+		return 0, reliability
+	}
+
+	ece := 0.0
+	for i := range reliability {
+		if reliability[i].Count == 0 {
+			continue
+		}
+		count := float64(reliability[i].Count)
+		reliability[i].MeanConfidence = confidenceSums[i] / count
+		reliability[i].Accuracy = float64(correctCounts[i]) / count
+		ece += count / float64(binned) * math.Abs(reliability[i].Accuracy-reliability[i].MeanConfidence)
+	}
+	return ece, reliability
+}
+
+// DecisionGrid evaluates a model with two input features over a steps x steps grid and returns the
+// predicted class at each point. Rows follow the y axis and columns the x axis, both including their endpoints.
+func DecisionGrid(model Predictor, xRange, yRange [2]float64, steps int) [][]string {
+	if steps <= 0 {
+		return nil
+	}
+
+	// gridValue maps a step index onto the range, placing a single step at the range start
+	gridValue := func(valueRange [2]float64, step int) float64 {
+		if steps == 1 {
+			return valueRange[0]
+		}
+		return valueRange[0] + (valueRange[1]-valueRange[0])*float64(step)/float64(steps-1)
+	}
+
+	grid := make([][]string, steps)
+	for row := 0; row < steps; row++ {
+		grid[row] = make([]string, steps)
+		y := gridValue(yRange, row)
+		for col := 0; col < steps; col++ {
+			x := gridValue(xRange, col)
+			output := model.Feedforward(map[string]interface{}{
+				"input": []float64{x, y},
+			})
+			grid[row][col] = PredictClass(output)
+		}
+	}
+	return grid
+}
+
+// LabelMetrics holds precision and recall for a single output treated as an independent binary label
+type LabelMetrics struct {
+	Precision float64
+	Recall    float64
+}
+
+// MultiLabelReport holds per-label metrics and the overall Hamming loss for multi-label evaluation
+type MultiLabelReport struct {
+	PerLabel    map[string]LabelMetrics
+	HammingLoss float64
+}
+
+// EvaluateMultiLabel treats every output independently instead of taking the argmax: an output is
+// predicted active when it reaches threshold, and expected active when its ExpectedOutput value is >= 0.5.
+// Hamming loss is the fraction of individual label decisions that are wrong.
+func EvaluateMultiLabel(model Predictor, sessions []blueprint.TrainingSession, threshold float64) MultiLabelReport {
+	truePositives := make(map[string]int)
+	falsePositives := make(map[string]int)
+	falseNegatives := make(map[string]int)
+	labels := make(map[string]bool)
+	wrongDecisions, totalDecisions := 0, 0
+
+	for _, session := range sessions {
+		output := model.Feedforward(session.InputVariables)
+		for key, value := range session.ExpectedOutput {
+			expectedValue, ok := toFloat64(value)
+			if !ok {
+				continue
+			}
+			labels[key] = true
+			expectedActive := expectedValue >= 0.5
+			predictedActive := output[key] >= threshold
+
+			switch {
+			case predictedActive && expectedActive:
+				truePositives[key]++
+			case predictedActive && !expectedActive:
+				falsePositives[key]++
+			case !predictedActive && expectedActive:
+				falseNegatives[key]++
+			}
+			if predictedActive != expectedActive {
+				wrongDecisions++
+			}
+			totalDecisions++
+		}
+	}
+
+	report := MultiLabelReport{PerLabel: make(map[string]LabelMetrics, len(labels))}
+	for key := range labels {
+		var metrics LabelMetrics
+		if predicted := truePositives[key] + falsePositives[key]; predicted > 0 {
+			metrics.Precision = float64(truePositives[key]) / float64(predicted)
+		}
+		if actual := truePositives[key] + falseNegatives[key]; actual > 0 {
+			metrics.Recall = float64(truePositives[key]) / float64(actual)
+		}
+		report.PerLabel[key] = metrics
+	}
+	if totalDecisions > 0 {
+		report.HammingLoss = float64(wrongDecisions) / float64(totalDecisions)
+	}
+	return report
+}
+
+// sessionLoss returns the cross-entropy of the expected class for one forward pass, clamping the
+// output away from zero so a confidently wrong prediction gives a large but finite loss
+func sessionLoss(output map[string]float64, session blueprint.TrainingSession) float64 {
+	probability := math.Max(output[expectedClass(session)], 1e-12)
+	return -math.Log(math.Min(probability, 1))
+}
+
+// PerSessionLoss returns the current cross-entropy loss of each session, in session order.
+// Sorting sessions by this value gives an easy-to-hard ordering for curriculum learning.
+func PerSessionLoss(model Predictor, sessions []blueprint.TrainingSession) []float64 {
+	losses := make([]float64, len(sessions))
+	for i, session := range sessions {
+		losses[i] = sessionLoss(model.Feedforward(session.InputVariables), session)
+	}
+	return losses
+}
+
+// SuspectedMislabeled returns the indices of the topN sessions with the highest loss, highest first.
+// Confidently wrong predictions are a common sign that a label is incorrect.
+func SuspectedMislabeled(model Predictor, sessions []blueprint.TrainingSession, topN int) []int {
+	if topN <= 0 {
+		return nil
+	}
+
+	losses := PerSessionLoss(model, sessions)
+	indices := make([]int, len(sessions))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	sort.SliceStable(indices, func(a, b int) bool {
+		return losses[indices[a]] > losses[indices[b]]
+	})
+
+	if topN < len(indices) {
+		indices = indices[:topN]
+	}
+	return indices
+}
+
+// classCounts returns how many sessions are labelled with each class
+func classCounts(sessions []blueprint.TrainingSession) map[string]int {
+	counts := make(map[string]int)
+	for _, session := range sessions {
+		counts[expectedClass(session)]++
+	}
+	return counts
+}
+
+// MajorityBaseline reports the accuracy of always predicting the most common class.
+// A model that does not beat this baseline has not learned anything useful.
+func MajorityBaseline(sessions []blueprint.TrainingSession) (class string, accuracy float64) {
+	if len(sessions) == 0 {
+		return "", 0
+	}
+
+	counts := classCounts(sessions)
+	classes := make([]string, 0, len(counts))
+	for key := range counts {
+		classes = append(classes, key)
+	}
+	sortClassKeys(classes)
+
+	for _, key := range classes {
+		if class == "" || counts[key] > counts[class] {
+			class = key
+		}
+	}
+	return class, float64(counts[class]) / float64(len(sessions))
+}
+
+// ConfusionMatrix runs the model over the sessions and counts predictions per expected class.
+// Rows are indexed by expected class and columns by predicted class, both following the returned class order.
+func ConfusionMatrix(model Predictor, sessions []blueprint.TrainingSession) ([]string, [][]int) {
+	expected := make([]string, len(sessions))
+	predicted := make([]string, len(sessions))
+	seen := make(map[string]bool)
+	for i, session := range sessions {
+		expected[i] = expectedClass(session)
+		predicted[i] = PredictClass(model.Feedforward(session.InputVariables))
+		seen[expected[i]] = true
+		seen[predicted[i]] = true
+	}
+
+	classes := make([]string, 0, len(seen))
+	for key := range seen {
+		classes = append(classes, key)
+	}
+	sortClassKeys(classes)
+
+	position := make(map[string]int, len(classes))
+	for i, key := range classes {
+		position[key] = i
+	}
+
+	matrix := make([][]int, len(classes))
+	for i := range matrix {
+		matrix[i] = make([]int, len(classes))
+	}
+	for i := range sessions {
+		matrix[position[expected[i]]][position[predicted[i]]]++
+	}
+	return classes, matrix
+}
+
+// BalancedAccuracy returns the mean per-class recall, which is not inflated by a dominant class the
+// way plain accuracy is. Classes that never appear as an expected label are left out of the average.
+func BalancedAccuracy(model Predictor, sessions []blueprint.TrainingSession) float64 {
+	_, matrix := ConfusionMatrix(model, sessions)
+
+	recallSum := 0.0
+	presentClasses := 0
+	for i, row := range matrix {
+		total := 0
+		for _, count := range row {
+			total += count
+		}
+		if total == 0 {
+			continue
+		}
+		recallSum += float64(row[i]) / float64(total)
+		presentClasses++
+	}
+
+	if presentClasses == 0 {
+		return 0
+	}
+	return recallSum / float64(presentClasses)
+}
+
+// NoiseRobustness measures accuracy (as a fraction) with Gaussian noise of each standard deviation
+// added to the input vectors. The seed makes the noise reproducible between runs. Sessions without a
+// []float64 vector under "input" cannot be perturbed and are left out of the accuracy.
+func NoiseRobustness(model Predictor, sessions []blueprint.TrainingSession, noiseLevels []float64, seed int64) map[float64]float64 {
+	results := make(map[float64]float64, len(noiseLevels))
+
+	var usable []blueprint.TrainingSession
+	for _, session := range sessions {
+		if len(sessionInput(session)) > 0 {
+			usable = append(usable, session)
+		}
+	}
+	if len(usable) == 0 {
+		return results
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	for _, level := range noiseLevels {
+		correct := 0
+		for _, session := range usable {
+			input := sessionInput(session)
+			noisy := make([]float64, len(input))
+			for i, value := range input {
+				noisy[i] = value + rng.NormFloat64()*level
+			}
+
+			// Copy the input map so the original session is left untouched
+			inputVariables := make(map[string]interface{}, len(session.InputVariables))
+			for key, value := range session.InputVariables {
+				inputVariables[key] = value
+			}
+			inputVariables["input"] = noisy
+
+			if PredictClass(model.Feedforward(inputVariables)) == expectedClass(session) {
+				correct++
+			}
+		}
+		results[level] = float64(correct) / float64(len(usable))
+	}
+	return results
+}
+
+// SessionBreakdown records how a single session scored under each of the three accuracy metrics
+type SessionBreakdown struct {
+	Index              int
+	Metrics            SetMetrics
+	ExactCorrect       bool
+	GenerousCorrect    bool
+	ForgivenessCorrect bool
+}
+
+// MetricBreakdown evaluates every session on its own with EvaluateModelPerformance, which shows how the
+// exact, generous and forgiveness metrics diverge. A metric counts as correct when it reaches 100%
+// for that session, allowing for rounding. The flags only read the percentages the evaluator reports,
+// so what "generous" and "forgiveness" accept is decided by the evaluator, not here.
+func MetricBreakdown(model Evaluator, sessions []blueprint.TrainingSession) []SessionBreakdown {
+	breakdown := make([]SessionBreakdown, len(sessions))
+	for i, session := range sessions {
+		metrics := evaluateSet(model, []blueprint.TrainingSession{session})
+		breakdown[i] = SessionBreakdown{
+			Index:              i,
+			Metrics:            metrics,
+			ExactCorrect:       metrics.ExactAccuracy >= fullAccuracy,
+			GenerousCorrect:    metrics.GenerousAccuracy >= fullAccuracy,
+			ForgivenessCorrect: metrics.ForgivenessAccuracy >= fullAccuracy,
+		}
+	}
+	return breakdown
+}
+
+// NormalizeToSimplex clips each output to [0, 1] and rescales the values to sum to 1, so sigmoid
+// outputs can be read as a probability distribution. The ordering of the outputs is preserved.
+// When every output clips to zero the distribution is uniform.
+func NormalizeToSimplex(output map[string]float64) map[string]float64 {
+	normalized := make(map[string]float64, len(output))
+	sum := 0.0
+	for key, value := range output {
+		clipped := math.Min(math.Max(value, 0), 1)
+		normalized[key] = clipped
+		sum += clipped
+	}
+
+	for key := range normalized {
+		if sum > 0 {
+			normalized[key] /= sum
+		} else {
+			normalized[key] = 1.0 / float64(len(normalized))
+		}
+	}
+	return normalized
+}
+
+// constantOutputVariance is the variance below which an output neuron is considered to never change
+const constantOutputVariance = 1e-12
+
+// OutputVariabilityReport holds the variance of each output across a dataset and the outputs that never change
+type OutputVariabilityReport struct {
+	Variance map[string]float64
+	Constant []string
+}
+
+// OutputVariability feeds every session forward and reports the variance of each output neuron.
+// Outputs that stay constant across the dataset are flagged, which usually means a dead or broken network.
+func OutputVariability(model Predictor, sessions []blueprint.TrainingSession) OutputVariabilityReport {
+	sums := make(map[string]float64)
+	squares := make(map[string]float64)
+	for _, session := range sessions {
+		for key, value := range model.Feedforward(session.InputVariables) {
+			sums[key] += value
+			squares[key] += value * value
+		}
+	}
+
+	report := OutputVariabilityReport{Variance: make(map[string]float64, len(sums))}
+	if len(sessions) == 0 {
+		return report
+	}
+
+	count := float64(len(sessions))
+	for key, sum := range sums {
+		mean := sum / count
+		variance := math.Max(squares[key]/count-mean*mean, 0)
+		report.Variance[key] = variance
+		if variance <= constantOutputVariance {
+			report.Constant = append(report.Constant, key)
+		}
+	}
+	sortClassKeys(report.Constant)
+	return report
+}
+
+// LearningCurvePoint holds the exact accuracy percentages reached when training on one fraction of the data
+type LearningCurvePoint struct {
+	Fraction           float64
+	TrainSize          int
+	TrainAccuracy      float64
+	ValidationAccuracy float64
+}
+
+// LearningCurve holds out the last 20% of the sessions for validation, matching setupModelTrainingSession,
+// then for each fraction calls trainFn on that share of the remaining sessions and evaluates the model.
+// trainFn is responsible for resetting the model if each point should start from scratch.
+func LearningCurve(model Evaluator, sessions []blueprint.TrainingSession, fractions []float64, trainFn func([]blueprint.TrainingSession)) []LearningCurvePoint {
+	splitIndex := int(float64(len(sessions)) * 0.8)
+	trainPool, validation := sessions[:splitIndex], sessions[splitIndex:]
+
+	curve := make([]LearningCurvePoint, 0, len(fractions))
+	for _, fraction := range fractions {
+		size := int(math.Round(float64(len(trainPool)) * math.Min(math.Max(fraction, 0), 1)))
+		subset := trainPool[:size]
+
+		trainFn(subset)
+
+		point := LearningCurvePoint{Fraction: fraction, TrainSize: size}
+		if size > 0 {
+			point.TrainAccuracy = evaluateSet(model, subset).ExactAccuracy
+		}
+		if len(validation) > 0 {
+			point.ValidationAccuracy = evaluateSet(model, validation).ExactAccuracy
+		}
+		curve = append(curve, point)
+	}
+	return curve
+}
+
+// ChanceLevel returns the accuracy (as a fraction) of guessing classes at random in proportion to how often
+// they occur, which is the sum of the squared class frequencies. For a balanced set this is 1/numClasses.
+func ChanceLevel(sessions []blueprint.TrainingSession) float64 {
+	if len(sessions) == 0 {
+		return 0
+	}
+
+	chance := 0.0
+	for _, count := range classCounts(sessions) {
+		frequency := float64(count) / float64(len(sessions))
+		chance += frequency * frequency
+	}
+	return chance
+}
+
+// MetricDefinition describes one of the six metrics returned by EvaluateModelPerformance,
+// together with the configuration values that currently affect it
+type MetricDefinition struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Source      string             `json:"source"`
+	Parameters  map[string]float64 `json:"parameters,omitempty"`
+}
+
+// metricSource names the function that computes every metric described by MetricDefinitions
+const metricSource = "blueprint.EvaluateModelPerformance"
+
+// MetricDefinitions returns a machine-readable description of the evaluation metrics, for dashboards
+// that need to explain the numbers. The metrics are computed inside the blueprint package, so the
+// descriptions only state what this program relies on (units, and which settings feed into which metric)
+// rather than the formulas themselves. Forgiveness metrics include the ForgivenessThreshold in effect.
+func MetricDefinitions(model *blueprint.Blueprint) []MetricDefinition {
+	forgiveness := map[string]float64{
+		"ForgivenessThreshold": model.Config.Metadata.ForgivenessThreshold,
+	}
+
+	return []MetricDefinition{
+		{Name: "ExactAccuracy", Source: metricSource, Description: "Exact accuracy, as a percentage. Stored as LastTrainingAccuracy and LastTestAccuracy."},
+		{Name: "GenerousAccuracy", Source: metricSource, Description: "Generous accuracy, as a percentage. Stored as LastTestAccuracyGenerous."},
+		{Name: "ForgivenessAccuracy", Source: metricSource, Description: "Forgiveness accuracy, as a percentage. Configured through ForgivenessThreshold and stored as LastTestAccuracyForgiveness.", Parameters: forgiveness},
+		{Name: "ExactErrorCount", Source: metricSource, Description: "Count of exact errors, the counterpart of ExactAccuracy."},
+		{Name: "AverageGenerousError", Source: metricSource, Description: "Average generous error, the counterpart of GenerousAccuracy."},
+		{Name: "ForgivenessErrorCount", Source: metricSource, Description: "Count of forgiveness errors, the counterpart of ForgivenessAccuracy. Configured through ForgivenessThreshold.", Parameters: forgiveness},
+	}
+}
+
+// EvaluateWithRejection abstains on predictions whose confidence is below confidenceThreshold.
+// It returns the accuracy over the accepted predictions and the coverage, the fraction of sessions accepted.
+func EvaluateWithRejection(model Predictor, sessions []blueprint.TrainingSession, confidenceThreshold float64) (accuracy, coverage float64) {
+	if len(sessions) == 0 {
+		return 0, 0
+	}
+
+	accepted, correct := 0, 0
+	for _, session := range sessions {
+		output := model.Feedforward(session.InputVariables)
+		predicted := PredictClass(output)
+		if output[predicted] < confidenceThreshold {
+			continue
+		}
+		accepted++
+		if predicted == expectedClass(session) {
+			correct++
+		}
+	}
+
+	if accepted > 0 {
+		accuracy = float64(correct) / float64(accepted)
+	}
+	return accuracy, float64(accepted) / float64(len(sessions))
+}
+
+// SuggestClassMerges returns pairs of classes whose mutual confusion rate exceeds minConfusionRate.
+// The mutual confusion rate of a pair is the number of sessions of either class predicted as the other,
+// divided by the number of sessions labelled with either class. The comparison is strict, so a pair
+// confused exactly minConfusionRate of the time is not suggested.
+func SuggestClassMerges(model Predictor, sessions []blueprint.TrainingSession, minConfusionRate float64) [][2]string {
+	classes, matrix := ConfusionMatrix(model, sessions)
+
+	rowTotals := make([]int, len(matrix))
+	for i, row := range matrix {
+		for _, count := range row {
+			rowTotals[i] += count
+		}
+	}
+
+	var merges [][2]string
+	for i := range classes {
+		for j := i + 1; j < len(classes); j++ {
+			total := rowTotals[i] + rowTotals[j]
+			if total == 0 {
+				continue
+			}
+			rate := float64(matrix[i][j]+matrix[j][i]) / float64(total)
+			if rate > minConfusionRate {
+				merges = append(merges, [2]string{classes[i], classes[j]})
+			}
+		}
+	}
+	return merges
+}
+
+// thresholdSample pairs one output value with whether that output was expected to be active
+type thresholdSample struct {
+	value  float64
+	active bool
+}
+
+// OptimalThresholds finds, for each output treated as a binary classifier, the threshold that maximizes
+// the chosen metric on the sessions. metric "youden" maximizes Youden's J (TPR - FPR); any other value
+// maximizes F1. Candidate thresholds are the observed output values, and an output is predicted active
+// when it reaches the threshold. Expected values of 0.5 and above count as active.
+func OptimalThresholds(model Predictor, sessions []blueprint.TrainingSession, metric string) map[string]float64 {
+	samples := make(map[string][]thresholdSample)
+	for _, session := range sessions {
+		output := model.Feedforward(session.InputVariables)
+		for key, value := range session.ExpectedOutput {
+			expectedValue, ok := toFloat64(value)
+			if !ok {
+				continue
+			}
+			samples[key] = append(samples[key], thresholdSample{value: output[key], active: expectedValue >= 0.5})
+		}
+	}
+
+	thresholds := make(map[string]float64, len(samples))
+	for key, keySamples := range samples {
+		thresholds[key] = optimalThreshold(keySamples, metric)
+	}
+	return thresholds
+}
+
+// optimalThreshold sorts the samples by value, highest first, and sweeps the candidate thresholds with
+// running true and false positive counts, which keeps the search at O(n log n) per output.
+// Non-finite values cannot serve as thresholds and would break the sweep, so those samples are dropped.
+func optimalThreshold(samples []thresholdSample, metric string) float64 {
+	finite := samples[:0:0]
+	for _, s := range samples {
+		if !math.IsNaN(s.value) && !math.IsInf(s.value, 0) {
+			finite = append(finite, s)
+		}
+	}
+	samples = finite
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].value > samples[j].value
+	})
+
+	positives := 0
+	for _, s := range samples {
+		if s.active {
+			positives++
+		}
+	}
+	negatives := len(samples) - positives
+
+	bestThreshold, bestScore := 0.5, math.Inf(-1)
+	truePositives, falsePositives := 0, 0
+	for i := 0; i < len(samples); {
+		// Every sample with the same value is predicted active at once, so consume them together
+		candidate := samples[i].value
+		for ; i < len(samples) && samples[i].value == candidate; i++ {
+			if samples[i].active {
+				truePositives++
+			} else {
+				falsePositives++
+			}
+		}
+
+		var score float64
+		if metric == "youden" {
+			var truePositiveRate, falsePositiveRate float64
+			if positives > 0 {
+				truePositiveRate = float64(truePositives) / float64(positives)
+			}
+			if negatives > 0 {
+				falsePositiveRate = float64(falsePositives) / float64(negatives)
+			}
+			score = truePositiveRate - falsePositiveRate
+		} else {
+			falseNegatives := positives - truePositives
+			if denominator := 2*truePositives + falsePositives + falseNegatives; denominator > 0 {
+				score = 2 * float64(truePositives) / float64(denominator)
+			}
+		}
+
+		// Candidates arrive from highest to lowest, so >= prefers the lower threshold on ties
+		if score >= bestScore {
+			bestThreshold, bestScore = candidate, score
+		}
+	}
+	return bestThreshold
+}
+
+// RetrainMispredicted evaluates every session on its own, collects the ones the model does not get
+// exactly right and calls trainFn on just those, a simple form of hard-example mining. Like LearningCurve
+// it leaves the actual training to trainFn. The mispredicted sessions are returned so the caller can
+// measure the improvement on them afterwards; trainFn is not called when there are none.
+func RetrainMispredicted(model Evaluator, sessions []blueprint.TrainingSession, trainFn func([]blueprint.TrainingSession)) []blueprint.TrainingSession {
+	var mispredicted []blueprint.TrainingSession
+	for _, breakdown := range MetricBreakdown(model, sessions) {
+		if !breakdown.ExactCorrect {
+			mispredicted = append(mispredicted, sessions[breakdown.Index])
+		}
+	}
+
+	if len(mispredicted) > 0 {
+		trainFn(mispredicted)
+	}
+	return mispredicted
+}
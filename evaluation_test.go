@@ -0,0 +1,690 @@
+package main
+
+import (
+	"blueprint"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestPredictClassBreaksTiesByLowestClassIndex(t *testing.T) {
+	output := map[string]float64{
+		"class_10": 0.9,
+		"class_2":  0.9,
+		"class_7":  0.9,
+		"class_1":  0.3,
+	}
+
+	// Repeat so a map-order dependent implementation would be caught
+	for i := 0; i < 50; i++ {
+		if got := PredictClass(output); got != "class_2" {
+			t.Fatalf("PredictClass() = %q, want class_2", got)
+		}
+	}
+}
+
+func TestPredictClassFallsBackToLexicographicOrder(t *testing.T) {
+	output := map[string]float64{"dog": 0.5, "cat": 0.5, "bird": 0.1}
+	if got := PredictClass(output); got != "cat" {
+		t.Fatalf("PredictClass() = %q, want cat", got)
+	}
+}
+
+func TestPredictClassOrdersIndexedKeysBeforeOthers(t *testing.T) {
+	output := map[string]float64{"class_10": 0.4, "class_1x": 0.4, "class_2": 0.4}
+
+	// Lexicographically class_10 < class_1x < class_2 while by index class_2 < class_10, so the
+	// order is only consistent when class_N keys are grouped before every other key
+	for i := 0; i < 50; i++ {
+		if got := PredictClass(output); got != "class_2" {
+			t.Fatalf("PredictClass() = %q, want class_2", got)
+		}
+	}
+
+	keys := []string{"class_1x", "class_10", "apple", "class_2"}
+	sortClassKeys(keys)
+	want := []string{"class_2", "class_10", "apple", "class_1x"}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("sortClassKeys() = %v, want %v", keys, want)
+		}
+	}
+}
+
+func TestSuspectedMislabeledNonPositiveTopN(t *testing.T) {
+	sessions := indexedSessions(3)
+	for _, topN := range []int{0, -1} {
+		if got := SuspectedMislabeled(nil, sessions, topN); got != nil {
+			t.Fatalf("SuspectedMislabeled(topN=%d) = %v, want nil", topN, got)
+		}
+	}
+}
+
+func TestSuspectedMislabeledRanksInjectedLabelFirst(t *testing.T) {
+	// thresholdModel predicts class_0 for session 0 and class_1 for the rest; session 3 is labelled against it
+	sessions := labelledSessions([]int{0, 1, 1, 0, 1, 1}, 2)
+
+	got := SuspectedMislabeled(thresholdModel(), sessions, 2)
+	if len(got) != 2 || got[0] != 3 {
+		t.Fatalf("SuspectedMislabeled() = %v, want session 3 first", got)
+	}
+}
+
+func TestPerSessionLossOnePerSessionNonNegative(t *testing.T) {
+	sessions := labelledSessions([]int{0, 1, 0, 1}, 2)
+
+	losses := PerSessionLoss(thresholdModel(), sessions)
+	if len(losses) != len(sessions) {
+		t.Fatalf("PerSessionLoss() returned %d values, want %d", len(losses), len(sessions))
+	}
+	for i, loss := range losses {
+		if loss < 0 || math.IsNaN(loss) || math.IsInf(loss, 0) {
+			t.Fatalf("loss %d = %v, want a finite non-negative value", i, loss)
+		}
+	}
+	// Session 2 is confidently wrong, so it must cost more than the correctly predicted session 1
+	if losses[2] <= losses[1] {
+		t.Fatalf("losses = %v, want session 2 above session 1", losses)
+	}
+}
+
+// labelledSessions builds one session per label with a one-hot ExpectedOutput over numClasses classes
+func labelledSessions(labels []int, numClasses int) []blueprint.TrainingSession {
+	sessions := make([]blueprint.TrainingSession, len(labels))
+	for i, label := range labels {
+		expectedOutput := make(map[string]interface{}, numClasses)
+		for class := 0; class < numClasses; class++ {
+			if class == label {
+				expectedOutput[fmt.Sprintf("class_%d", class)] = 1.0
+			} else {
+				expectedOutput[fmt.Sprintf("class_%d", class)] = 0.0
+			}
+		}
+		sessions[i] = blueprint.TrainingSession{
+			InputVariables: map[string]interface{}{"input": []float64{float64(i)}},
+			ExpectedOutput: expectedOutput,
+		}
+	}
+	return sessions
+}
+
+// fakeModel stands in for a trained blueprint, computing its output from the "input" vector
+type fakeModel struct {
+	predict func(input []float64) map[string]float64
+}
+
+func (m *fakeModel) Feedforward(inputVariables map[string]interface{}) map[string]float64 {
+	input, _ := inputVariables["input"].([]float64)
+	return m.predict(input)
+}
+
+// EvaluateModelPerformance scores exact accuracy by argmax and derives the generous values from the
+// probability given to the expected class, so each of the six results is distinguishable in tests
+func (m *fakeModel) EvaluateModelPerformance(sessions []blueprint.TrainingSession) (float64, float64, float64, float64, float64, float64) {
+	if len(sessions) == 0 {
+		return 0, 0, 0, 0, 0, 0
+	}
+	correct, probability := 0, 0.0
+	for _, session := range sessions {
+		output := m.Feedforward(session.InputVariables)
+		expected := expectedClass(session)
+		if PredictClass(output) == expected {
+			correct++
+		}
+		probability += output[expected]
+	}
+	count := float64(len(sessions))
+	errors := count - float64(correct)
+	generous := probability / count * 100
+	return float64(correct) / count * 100, generous, generous / 2, errors, 100 - generous, errors / 2
+}
+
+// thresholdModel predicts class_1 when the first input is positive and class_0 otherwise
+func thresholdModel() *fakeModel {
+	return &fakeModel{predict: func(input []float64) map[string]float64 {
+		if len(input) > 0 && input[0] > 0 {
+			return map[string]float64{"class_0": 0.2, "class_1": 0.8}
+		}
+		return map[string]float64{"class_0": 0.7, "class_1": 0.3}
+	}}
+}
+
+func TestFullReportMatchesEvaluateModelPerformance(t *testing.T) {
+	model := thresholdModel()
+	train := labelledSessions([]int{0, 1, 1, 1}, 2)
+	test := labelledSessions([]int{1, 0, 0}, 2)
+
+	report := FullReport(model, train, test)
+	for _, set := range []struct {
+		name     string
+		metrics  SetMetrics
+		sessions []blueprint.TrainingSession
+	}{
+		{"training", report.Training, train},
+		{"testing", report.Testing, test},
+	} {
+		exact, generous, forgiveness, exactErrors, generousError, forgivenessErrors := model.EvaluateModelPerformance(set.sessions)
+		got := [6]float64{set.metrics.ExactAccuracy, set.metrics.GenerousAccuracy, set.metrics.ForgivenessAccuracy,
+			set.metrics.ExactErrorCount, set.metrics.AverageGenerousError, set.metrics.ForgivenessErrorCount}
+		want := [6]float64{exact, generous, forgiveness, exactErrors, generousError, forgivenessErrors}
+		if got != want {
+			t.Fatalf("%s metrics = %v, want %v", set.name, got, want)
+		}
+		if set.metrics.AboveChance != set.metrics.ExactAccuracy-set.metrics.ChanceLevel {
+			t.Fatalf("%s AboveChance = %v, want %v", set.name, set.metrics.AboveChance, set.metrics.ExactAccuracy-set.metrics.ChanceLevel)
+		}
+	}
+}
+
+func TestCompareModelsAgainstItself(t *testing.T) {
+	model := thresholdModel()
+	sessions := labelledSessions([]int{0, 1, 1, 0, 1}, 2)
+
+	agreement, meanAbsDiff := CompareModels(model, model, sessions)
+	if agreement != 1 || meanAbsDiff != 0 {
+		t.Fatalf("CompareModels(model, model) = (%v, %v), want (1, 0)", agreement, meanAbsDiff)
+	}
+}
+
+// confidenceModel predicts class_1 with the confidence given as the first input
+func confidenceModel() *fakeModel {
+	return &fakeModel{predict: func(input []float64) map[string]float64 {
+		return map[string]float64{"class_0": 1 - input[0], "class_1": input[0]}
+	}}
+}
+
+// calibratedSessions builds count sessions with the given confidence, of which correct are labelled class_1
+func calibratedSessions(confidence float64, count, correct int) []blueprint.TrainingSession {
+	sessions := make([]blueprint.TrainingSession, count)
+	for i := range sessions {
+		label := 0.0
+		if i < correct {
+			label = 1
+		}
+		sessions[i] = blueprint.TrainingSession{
+			InputVariables: map[string]interface{}{"input": []float64{confidence}},
+			ExpectedOutput: map[string]interface{}{"class_0": 1 - label, "class_1": label},
+		}
+	}
+	return sessions
+}
+
+func TestCalibrationErrorPerfectlyCalibrated(t *testing.T) {
+	// Each confidence level is right exactly as often as it claims
+	sessions := append(calibratedSessions(0.6, 10, 6), calibratedSessions(0.9, 10, 9)...)
+
+	ece, bins := CalibrationError(confidenceModel(), sessions, 10)
+	if ece > 1e-9 {
+		t.Fatalf("CalibrationError() = %v, want ~0", ece)
+	}
+	if bins[6].Count != 10 || bins[9].Count != 10 {
+		t.Fatalf("bin counts = %d and %d, want 10 and 10", bins[6].Count, bins[9].Count)
+	}
+}
+
+func TestCalibrationErrorSkipsNonFiniteConfidence(t *testing.T) {
+	sessions := append(calibratedSessions(math.NaN(), 1, 0), calibratedSessions(0.9, 10, 9)...)
+
+	ece, bins := CalibrationError(confidenceModel(), sessions, 10)
+	if ece > 1e-9 {
+		t.Fatalf("CalibrationError() = %v, want ~0", ece)
+	}
+	total := 0
+	for _, bin := range bins {
+		total += bin.Count
+	}
+	if total != 10 {
+		t.Fatalf("binned %d sessions, want 10", total)
+	}
+}
+
+func TestDecisionGridOnSeparableModel(t *testing.T) {
+	// The model splits the plane at x = 0 and ignores y, so every row must show the same boundary
+	grid := DecisionGrid(thresholdModel(), [2]float64{-1, 1}, [2]float64{-5, 5}, 4)
+	if len(grid) != 4 {
+		t.Fatalf("DecisionGrid() has %d rows, want 4", len(grid))
+	}
+	want := []string{"class_0", "class_0", "class_1", "class_1"}
+	for row, classes := range grid {
+		if len(classes) != len(want) {
+			t.Fatalf("row %d has %d columns, want %d", row, len(classes), len(want))
+		}
+		for col := range want {
+			if classes[col] != want[col] {
+				t.Fatalf("row %d = %v, want %v", row, classes, want)
+			}
+		}
+	}
+}
+
+func TestEvaluateMultiLabelWithTwoActiveLabels(t *testing.T) {
+	outputs := [][3]float64{{0.9, 0.8, 0.1}, {0.2, 0.7, 0.9}}
+	model := &fakeModel{predict: func(input []float64) map[string]float64 {
+		output := outputs[int(input[0])]
+		return map[string]float64{"a": output[0], "b": output[1], "c": output[2]}
+	}}
+	sessions := []blueprint.TrainingSession{
+		{
+			InputVariables: map[string]interface{}{"input": []float64{0}},
+			ExpectedOutput: map[string]interface{}{"a": 1.0, "b": 1.0, "c": 0.0},
+		},
+		{
+			InputVariables: map[string]interface{}{"input": []float64{1}},
+			ExpectedOutput: map[string]interface{}{"a": 1.0, "b": 0.0, "c": 1.0},
+		},
+	}
+
+	// Session 0 gets every label right; session 1 misses a and wrongly activates b
+	report := EvaluateMultiLabel(model, sessions, 0.5)
+	want := map[string]LabelMetrics{
+		"a": {Precision: 1, Recall: 0.5},
+		"b": {Precision: 0.5, Recall: 1},
+		"c": {Precision: 1, Recall: 1},
+	}
+	for label, metrics := range want {
+		if report.PerLabel[label] != metrics {
+			t.Fatalf("label %s = %+v, want %+v", label, report.PerLabel[label], metrics)
+		}
+	}
+	if math.Abs(report.HammingLoss-2.0/6) > 1e-12 {
+		t.Fatalf("HammingLoss = %v, want %v", report.HammingLoss, 2.0/6)
+	}
+}
+
+// exactAccuracy returns the fraction of sessions whose predicted class matches the label
+func exactAccuracy(model Predictor, sessions []blueprint.TrainingSession) float64 {
+	correct := 0
+	for _, session := range sessions {
+		if PredictClass(model.Feedforward(session.InputVariables)) == expectedClass(session) {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(sessions))
+}
+
+func TestRetrainMispredictedImprovesMispredictedSubset(t *testing.T) {
+	// The model predicts class_0 until trainFn memorises the label for an input
+	memory := make(map[float64]string)
+	model := &fakeModel{predict: func(input []float64) map[string]float64 {
+		if class, ok := memory[input[0]]; ok {
+			return map[string]float64{class: 1}
+		}
+		return map[string]float64{"class_0": 1}
+	}}
+	trainFn := func(batch []blueprint.TrainingSession) {
+		for _, session := range batch {
+			memory[sessionInput(session)[0]] = expectedClass(session)
+		}
+	}
+	sessions := labelledSessions([]int{0, 1, 1, 0, 1}, 2)
+
+	mispredicted := RetrainMispredicted(model, sessions, trainFn)
+	if len(mispredicted) != 3 {
+		t.Fatalf("RetrainMispredicted() returned %d sessions, want the 3 labelled class_1", len(mispredicted))
+	}
+	if len(memory) != 3 {
+		t.Fatalf("trainFn saw %d sessions, want only the 3 mispredicted ones", len(memory))
+	}
+	if got := exactAccuracy(model, mispredicted); got != 1 {
+		t.Fatalf("accuracy on the mispredicted subset after retraining = %v, want 1 (was 0)", got)
+	}
+}
+
+// majorityModel always predicts class_0
+func majorityModel() *fakeModel {
+	return &fakeModel{predict: func(input []float64) map[string]float64 {
+		return map[string]float64{"class_0": 0.9, "class_1": 0.1}
+	}}
+}
+
+func TestBalancedAccuracyDiffersOnImbalancedSet(t *testing.T) {
+	sessions := labelledSessions([]int{0, 0, 0, 0, 0, 0, 0, 0, 1, 1}, 2)
+	model := majorityModel()
+
+	classes, matrix := ConfusionMatrix(model, sessions)
+	if len(classes) != 2 || classes[0] != "class_0" || classes[1] != "class_1" {
+		t.Fatalf("ConfusionMatrix() classes = %v, want [class_0 class_1]", classes)
+	}
+	if matrix[0][0] != 8 || matrix[0][1] != 0 || matrix[1][0] != 2 || matrix[1][1] != 0 {
+		t.Fatalf("ConfusionMatrix() = %v, want [[8 0] [2 0]]", matrix)
+	}
+
+	// Always answering the majority class scores 80% plainly but only 50% once each class counts equally
+	if accuracy := exactAccuracy(model, sessions); math.Abs(accuracy-0.8) > 1e-12 {
+		t.Fatalf("accuracy = %v, want 0.8", accuracy)
+	}
+	if balanced := BalancedAccuracy(model, sessions); math.Abs(balanced-0.5) > 1e-12 {
+		t.Fatalf("BalancedAccuracy() = %v, want 0.5", balanced)
+	}
+}
+
+// signedSessions alternates inputs of -1 labelled class_0 and +1 labelled class_1, which thresholdModel separates
+func signedSessions(count int) []blueprint.TrainingSession {
+	sessions := make([]blueprint.TrainingSession, count)
+	for i := range sessions {
+		input, label := -1.0, 0.0
+		if i%2 == 1 {
+			input, label = 1, 1
+		}
+		sessions[i] = blueprint.TrainingSession{
+			InputVariables: map[string]interface{}{"input": []float64{input}},
+			ExpectedOutput: map[string]interface{}{"class_0": 1 - label, "class_1": label},
+		}
+	}
+	return sessions
+}
+
+func TestNoiseRobustnessDegradesWithNoise(t *testing.T) {
+	levels := []float64{0, 0.5, 2, 10}
+	results := NoiseRobustness(thresholdModel(), signedSessions(1000), levels, 7)
+
+	if results[0] != 1 {
+		t.Fatalf("accuracy without noise = %v, want 1", results[0])
+	}
+	// Allow a little sampling slack between neighbouring levels, but the trend must be downwards
+	for i := 1; i < len(levels); i++ {
+		if results[levels[i]] > results[levels[i-1]]+0.02 {
+			t.Fatalf("accuracy rose from %v at noise %v to %v at noise %v", results[levels[i-1]], levels[i-1], results[levels[i]], levels[i])
+		}
+	}
+	if results[10] > 0.7 {
+		t.Fatalf("accuracy at noise 10 = %v, want close to chance", results[10])
+	}
+}
+
+// scoredModel reports fixed exact, generous and forgiveness accuracies for each single-session evaluation,
+// looked up by the session's input value
+type scoredModel struct {
+	scores [][3]float64
+}
+
+func (m *scoredModel) Feedforward(inputVariables map[string]interface{}) map[string]float64 {
+	return nil
+}
+
+func (m *scoredModel) EvaluateModelPerformance(sessions []blueprint.TrainingSession) (float64, float64, float64, float64, float64, float64) {
+	score := m.scores[int(sessionInput(sessions[0])[0])]
+	return score[0], score[1], score[2], 0, 0, 0
+}
+
+func TestMetricBreakdownCoversEveryCombination(t *testing.T) {
+	// One session per combination of the three flags, with values just around the 100% boundary
+	model := &scoredModel{}
+	var want [][3]bool
+	for combination := 0; combination < 8; combination++ {
+		var score [3]float64
+		var flags [3]bool
+		for metric := 0; metric < 3; metric++ {
+			flags[metric] = combination&(1<<metric) != 0
+			if flags[metric] {
+				score[metric] = 100 - 1e-12
+			} else {
+				score[metric] = 99.99
+			}
+		}
+		model.scores = append(model.scores, score)
+		want = append(want, flags)
+	}
+
+	breakdown := MetricBreakdown(model, indexedSessions(len(model.scores)))
+	if len(breakdown) != len(want) {
+		t.Fatalf("MetricBreakdown() returned %d entries, want %d", len(breakdown), len(want))
+	}
+	for i, entry := range breakdown {
+		got := [3]bool{entry.ExactCorrect, entry.GenerousCorrect, entry.ForgivenessCorrect}
+		if entry.Index != i || got != want[i] {
+			t.Fatalf("entry %d = index %d flags %v, want index %d flags %v", i, entry.Index, got, i, want[i])
+		}
+		if entry.Metrics.GenerousAccuracy != model.scores[i][1] {
+			t.Fatalf("entry %d generous accuracy = %v, want %v", i, entry.Metrics.GenerousAccuracy, model.scores[i][1])
+		}
+	}
+}
+
+func TestOutputVariabilityFlagsConstantOutput(t *testing.T) {
+	// class_1 follows the input while class_0 is stuck, as a dead output neuron would be
+	model := &fakeModel{predict: func(input []float64) map[string]float64 {
+		return map[string]float64{"class_0": 0.5, "class_1": input[0] / 10}
+	}}
+
+	report := OutputVariability(model, indexedSessions(10))
+	if len(report.Constant) != 1 || report.Constant[0] != "class_0" {
+		t.Fatalf("OutputVariability() constant = %v, want [class_0]", report.Constant)
+	}
+	if report.Variance["class_1"] <= constantOutputVariance {
+		t.Fatalf("class_1 variance = %v, want it above %v", report.Variance["class_1"], constantOutputVariance)
+	}
+}
+
+func TestLearningCurveSplitsAndImproves(t *testing.T) {
+	labels := make([]int, 100)
+	for i := 50; i < len(labels); i++ {
+		labels[i] = 1
+	}
+	sessions := labelledSessions(labels, 2)
+
+	// A nearest-neighbour model that trainFn refits from scratch on every call
+	var memory []blueprint.TrainingSession
+	model := &fakeModel{predict: func(input []float64) map[string]float64 {
+		best, bestDistance := "class_0", math.Inf(1)
+		for _, session := range memory {
+			if distance := math.Abs(sessionInput(session)[0] - input[0]); distance < bestDistance {
+				best, bestDistance = expectedClass(session), distance
+			}
+		}
+		return map[string]float64{best: 1}
+	}}
+	var trainSizes []int
+	trainFn := func(subset []blueprint.TrainingSession) {
+		for _, session := range subset {
+			if sessionInput(session)[0] >= 80 {
+				t.Fatalf("trainFn received validation session %v", sessionInput(session)[0])
+			}
+		}
+		memory = subset
+		trainSizes = append(trainSizes, len(subset))
+	}
+
+	curve := LearningCurve(model, sessions, []float64{0.1, 0.5, 1}, trainFn)
+	wantSizes := []int{8, 40, 80}
+	if len(curve) != len(wantSizes) || len(trainSizes) != len(wantSizes) {
+		t.Fatalf("LearningCurve() returned %d points after %d trainFn calls, want %d", len(curve), len(trainSizes), len(wantSizes))
+	}
+	for i, point := range curve {
+		if point.TrainSize != wantSizes[i] || trainSizes[i] != wantSizes[i] {
+			t.Fatalf("point %d trained on %d (trainFn saw %d), want %d", i, point.TrainSize, trainSizes[i], wantSizes[i])
+		}
+		if point.TrainAccuracy != 100 {
+			t.Fatalf("point %d train accuracy = %v, want 100", i, point.TrainAccuracy)
+		}
+		if i > 0 && point.ValidationAccuracy < curve[i-1].ValidationAccuracy {
+			t.Fatalf("validation accuracy fell from %v to %v", curve[i-1].ValidationAccuracy, point.ValidationAccuracy)
+		}
+	}
+	if last := curve[len(curve)-1].ValidationAccuracy; last != 100 {
+		t.Fatalf("validation accuracy with all training data = %v, want 100", last)
+	}
+}
+
+func TestEvaluateWithRejectionTradesCoverageForAccuracy(t *testing.T) {
+	sessions := append(calibratedSessions(0.6, 10, 6), calibratedSessions(0.9, 10, 9)...)
+	model := confidenceModel()
+
+	lowAccuracy, lowCoverage := EvaluateWithRejection(model, sessions, 0.5)
+	highAccuracy, highCoverage := EvaluateWithRejection(model, sessions, 0.8)
+	if math.Abs(lowAccuracy-0.75) > 1e-12 || lowCoverage != 1 {
+		t.Fatalf("threshold 0.5 = (%v, %v), want (0.75, 1)", lowAccuracy, lowCoverage)
+	}
+	if math.Abs(highAccuracy-0.9) > 1e-12 || highCoverage != 0.5 {
+		t.Fatalf("threshold 0.8 = (%v, %v), want (0.9, 0.5)", highAccuracy, highCoverage)
+	}
+}
+
+// predictionModel predicts the class listed for each session, indexed by the session's input value
+func predictionModel(predictions []int) *fakeModel {
+	return &fakeModel{predict: func(input []float64) map[string]float64 {
+		return map[string]float64{fmt.Sprintf("class_%d", predictions[int(input[0])]): 1}
+	}}
+}
+
+func TestSuggestClassMergesFindsConfusedPair(t *testing.T) {
+	// class_0 and class_1 are swapped half of the time, class_2 is always right
+	sessions := labelledSessions([]int{0, 0, 1, 1, 2, 2}, 3)
+	model := predictionModel([]int{1, 0, 0, 1, 2, 2})
+
+	got := SuggestClassMerges(model, sessions, 0.3)
+	if len(got) != 1 || got[0] != [2]string{"class_0", "class_1"} {
+		t.Fatalf("SuggestClassMerges() = %v, want [[class_0 class_1]]", got)
+	}
+	// The pair's rate is exactly 0.5, which does not exceed a minimum of 0.5
+	if got := SuggestClassMerges(model, sessions, 0.5); len(got) != 0 {
+		t.Fatalf("SuggestClassMerges(0.5) = %v, want no pairs", got)
+	}
+}
+
+func TestMajorityBaselineOnImbalancedSet(t *testing.T) {
+	sessions := labelledSessions([]int{2, 2, 2, 2, 2, 2, 2, 0, 1, 1}, 3)
+
+	class, accuracy := MajorityBaseline(sessions)
+	if class != "class_2" || math.Abs(accuracy-0.7) > 1e-12 {
+		t.Fatalf("MajorityBaseline() = (%q, %v), want (class_2, 0.7)", class, accuracy)
+	}
+}
+
+func TestNoiseRobustnessSkipsSessionsWithoutInput(t *testing.T) {
+	sessions := []blueprint.TrainingSession{
+		{InputVariables: map[string]interface{}{"pixels": []float64{1, 2}}},
+		{InputVariables: map[string]interface{}{"input": []interface{}{1.0}}},
+	}
+
+	// No session has a usable input vector, so the model is never run and no accuracy is reported
+	if got := NoiseRobustness(nil, sessions, []float64{0, 0.5}, 1); len(got) != 0 {
+		t.Fatalf("NoiseRobustness() = %v, want no results", got)
+	}
+}
+
+func TestNormalizeToSimplexSumsToOneAndKeepsOrder(t *testing.T) {
+	output := map[string]float64{"class_0": 0.9, "class_1": 0.6, "class_2": 0.1, "class_3": -0.2}
+
+	normalized := NormalizeToSimplex(output)
+	sum := 0.0
+	for _, value := range normalized {
+		if value < 0 || value > 1 {
+			t.Fatalf("value %v is outside [0, 1]", value)
+		}
+		sum += value
+	}
+	if math.Abs(sum-1) > 1e-12 {
+		t.Fatalf("normalized outputs sum to %v, want 1", sum)
+	}
+	if !(normalized["class_0"] > normalized["class_1"] && normalized["class_1"] > normalized["class_2"] && normalized["class_2"] >= normalized["class_3"]) {
+		t.Fatalf("ordering changed: %v", normalized)
+	}
+}
+
+func TestNormalizeToSimplexAllZeroIsUniform(t *testing.T) {
+	normalized := NormalizeToSimplex(map[string]float64{"a": 0, "b": -1})
+	if normalized["a"] != 0.5 || normalized["b"] != 0.5 {
+		t.Fatalf("NormalizeToSimplex() = %v, want a uniform distribution", normalized)
+	}
+}
+
+func TestChanceLevelOnBalancedTenClassSet(t *testing.T) {
+	labels := make([]int, 100)
+	for i := range labels {
+		labels[i] = i % 10
+	}
+
+	if chance := ChanceLevel(labelledSessions(labels, 10)); math.Abs(chance-0.1) > 1e-12 {
+		t.Fatalf("ChanceLevel() = %v, want 0.1", chance)
+	}
+}
+
+func TestChanceLevelUsesClassFrequencies(t *testing.T) {
+	// 3/4 of one class and 1/4 of another gives 0.75^2 + 0.25^2
+	sessions := labelledSessions([]int{0, 0, 0, 1}, 2)
+	if chance := ChanceLevel(sessions); math.Abs(chance-0.625) > 1e-12 {
+		t.Fatalf("ChanceLevel() = %v, want 0.625", chance)
+	}
+}
+
+func TestMetricDefinitionsReportForgivenessThreshold(t *testing.T) {
+	model := blueprint.NewBlueprint(nil)
+	model.Config.Metadata.ForgivenessThreshold = 0.8
+
+	definitions := MetricDefinitions(model)
+	if len(definitions) != 6 {
+		t.Fatalf("MetricDefinitions() returned %d metrics, want 6", len(definitions))
+	}
+	for _, definition := range definitions {
+		threshold, ok := definition.Parameters["ForgivenessThreshold"]
+		isForgiveness := definition.Name == "ForgivenessAccuracy" || definition.Name == "ForgivenessErrorCount"
+		if ok != isForgiveness {
+			t.Fatalf("%s: has ForgivenessThreshold = %v, want %v", definition.Name, ok, isForgiveness)
+		}
+		if ok && threshold != 0.8 {
+			t.Fatalf("%s: ForgivenessThreshold = %v, want 0.8", definition.Name, threshold)
+		}
+	}
+}
+
+func TestOptimalThresholdOnSkewedOutput(t *testing.T) {
+	// Every active sample scores at least 0.2 and every inactive one at most 0.15
+	samples := []thresholdSample{
+		{0.05, false}, {0.1, false}, {0.15, false}, {0.12, false},
+		{0.2, true}, {0.25, true}, {0.3, true}, {0.22, true},
+	}
+
+	for _, metric := range []string{"f1", "youden"} {
+		if got := optimalThreshold(append([]thresholdSample(nil), samples...), metric); got != 0.2 {
+			t.Errorf("optimalThreshold(%s) = %v, want 0.2", metric, got)
+		}
+	}
+}
+
+func TestOptimalThresholdIgnoresNaN(t *testing.T) {
+	// A NaN never equals itself, so an unfiltered sweep would stop advancing and hang
+	samples := []thresholdSample{{math.NaN(), true}, {0.3, true}, {math.Inf(1), false}, {0.1, false}}
+	for _, metric := range []string{"f1", "youden"} {
+		if got := optimalThreshold(append([]thresholdSample(nil), samples...), metric); got != 0.3 {
+			t.Errorf("optimalThreshold(%s) = %v, want 0.3", metric, got)
+		}
+	}
+}
+
+// scoredSessions builds one session per value, labelled class_1 when active and class_0 otherwise
+func scoredSessions(values []float64, active bool) []blueprint.TrainingSession {
+	label := 0.0
+	if active {
+		label = 1
+	}
+	sessions := make([]blueprint.TrainingSession, len(values))
+	for i, value := range values {
+		sessions[i] = blueprint.TrainingSession{
+			InputVariables: map[string]interface{}{"input": []float64{value}},
+			ExpectedOutput: map[string]interface{}{"class_0": 1 - label, "class_1": label},
+		}
+	}
+	return sessions
+}
+
+func TestOptimalThresholdsOnSkewedModel(t *testing.T) {
+	// class_1 never reaches 0.5 even when active, so the default threshold would miss every positive
+	sessions := append(scoredSessions([]float64{0.2, 0.25, 0.3}, true), scoredSessions([]float64{0.05, 0.1, 0.15}, false)...)
+	sessions = append(sessions, scoredSessions([]float64{math.NaN()}, true)...)
+
+	for _, metric := range []string{"f1", "youden"} {
+		thresholds := OptimalThresholds(confidenceModel(), sessions, metric)
+		if math.Abs(thresholds["class_1"]-0.2) > 1e-12 || math.Abs(thresholds["class_0"]-0.85) > 1e-12 {
+			t.Errorf("OptimalThresholds(%s) = %v, want class_0 0.85 and class_1 0.2", metric, thresholds)
+		}
+	}
+}
+
+func TestOptimalThresholdGroupsEqualValues(t *testing.T) {
+	// Both samples at 0.4 must be counted together; splitting them would invent a better threshold
+	samples := []thresholdSample{{0.4, true}, {0.4, false}, {0.1, false}}
+	if got := optimalThreshold(samples, "f1"); got != 0.4 {
+		t.Fatalf("optimalThreshold() = %v, want 0.4", got)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"blueprint"
+	"encoding/csv"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ExportPredictions writes one CSV row per session with the predicted class, its confidence and the true class.
+// When includeProbabilities is set, the full output vector is appended as one column per output key.
+func ExportPredictions(model Predictor, sessions []blueprint.TrainingSession, path string, includeProbabilities bool) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create predictions file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	var outputKeys []string
+	for i, session := range sessions {
+		output := model.Feedforward(session.InputVariables)
+
+		// The header is written from the first output so the probability columns match the model's keys
+		if i == 0 {
+			header := []string{"index", "predicted", "confidence", "expected"}
+			if includeProbabilities {
+				for key := range output {
+					outputKeys = append(outputKeys, key)
+				}
+				sortClassKeys(outputKeys)
+				header = append(header, outputKeys...)
+			}
+			if err := writer.Write(header); err != nil {
+				return fmt.Errorf("failed to write predictions header: %w", err)
+			}
+		}
+
+		predicted := PredictClass(output)
+		row := []string{
+			strconv.Itoa(i),
+			predicted,
+			strconv.FormatFloat(output[predicted], 'f', 6, 64),
+			expectedClass(session),
+		}
+		for _, key := range outputKeys {
+			row = append(row, strconv.FormatFloat(output[key], 'f', 6, 64))
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write prediction row %d: %w", i, err)
+		}
+	}
+
+	if len(sessions) == 0 {
+		if err := writer.Write([]string{"index", "predicted", "confidence", "expected"}); err != nil {
+			return fmt.Errorf("failed to write predictions header: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// MetricsPrometheus renders the latest accuracy and error values from Config.Metadata in the
+// Prometheus text exposition format so they can be scraped by existing monitoring
+func MetricsPrometheus(model *blueprint.Blueprint) string {
+	metadata := model.Config.Metadata
+	metrics := []struct {
+		name  string
+		help  string
+		value float64
+	}{
+		{"layerforge_training_accuracy_percent", "Exact accuracy on the training set from the last evaluation.", metadata.LastTrainingAccuracy},
+		{"layerforge_test_accuracy_percent", "Exact accuracy on the testing set from the last evaluation.", metadata.LastTestAccuracy},
+		{"layerforge_test_generous_accuracy_percent", "Generous accuracy on the testing set from the last evaluation.", metadata.LastTestAccuracyGenerous},
+		{"layerforge_test_forgiveness_accuracy_percent", "Forgiveness accuracy on the testing set from the last evaluation.", metadata.LastTestAccuracyForgiveness},
+		{"layerforge_training_exact_errors", "Exact error count on the training set from the last evaluation.", metadata.LastTrainingExactErrorCount},
+		{"layerforge_test_exact_errors", "Exact error count on the testing set from the last evaluation.", metadata.LastTestExactErrorCount},
+		{"layerforge_training_average_generous_error", "Average generous error on the training set from the last evaluation.", metadata.LastTrainingAverageGenerousError},
+		{"layerforge_test_average_generous_error", "Average generous error on the testing set from the last evaluation.", metadata.LastTestAverageGenerousError},
+		{"layerforge_training_forgiveness_errors", "Forgiveness error count on the training set from the last evaluation.", metadata.LastTrainingForgivenessErrorCount},
+		{"layerforge_test_forgiveness_errors", "Forgiveness error count on the testing set from the last evaluation.", metadata.LastTestForgivenessErrorCount},
+		{"layerforge_total_neurons", "Total number of neurons in the model.", float64(metadata.TotalNeurons)},
+		{"layerforge_total_layers", "Total number of layers in the model.", float64(metadata.TotalLayers)},
+	}
+
+	var builder strings.Builder
+	for _, metric := range metrics {
+		fmt.Fprintf(&builder, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(&builder, "# TYPE %s gauge\n", metric.name)
+		fmt.Fprintf(&builder, "%s %s\n", metric.name, strconv.FormatFloat(metric.value, 'g', -1, 64))
+	}
+	return builder.String()
+}
+
+const (
+	confusionCellSize    = 32 // Width and height of one matrix cell in pixels
+	confusionLabelMargin = 24 // Space left of and above the matrix for the axis labels
+	confusionDigitScale  = 2  // Each font pixel is drawn as a square of this size
+)
+
+// confusionDigits is a 3x5 bitmap font for the digits used as axis labels, one row per string
+var confusionDigits = [10][5]string{
+	{"111", "101", "101", "101", "111"},
+	{"010", "110", "010", "010", "111"},
+	{"111", "001", "111", "100", "111"},
+	{"111", "001", "111", "001", "111"},
+	{"101", "101", "111", "001", "001"},
+	{"111", "100", "111", "001", "111"},
+	{"111", "100", "111", "101", "111"},
+	{"111", "001", "001", "001", "001"},
+	{"111", "101", "111", "101", "111"},
+	{"111", "101", "111", "001", "111"},
+}
+
+// drawNumber draws a non-negative number with the bitmap font, its top-left corner at (x, y)
+func drawNumber(img *image.RGBA, number, x, y int) {
+	for _, digit := range strconv.Itoa(number) {
+		glyph := confusionDigits[digit-'0']
+		for row, line := range glyph {
+			for col, pixel := range line {
+				if pixel != '1' {
+					continue
+				}
+				for dy := 0; dy < confusionDigitScale; dy++ {
+					for dx := 0; dx < confusionDigitScale; dx++ {
+						img.Set(x+col*confusionDigitScale+dx, y+row*confusionDigitScale+dy, color.Black)
+					}
+				}
+			}
+		}
+		x += 4 * confusionDigitScale
+	}
+}
+
+// SaveConfusionMatrixImage renders the confusion matrix as a PNG heatmap, darker cells meaning higher counts.
+// Rows are expected classes and columns predicted classes. The image has no text font, so axis labels are
+// numbers: when every key has the form class_N the label is N, otherwise every label is the class position.
+// The class keys are returned in axis order as a legend, so legend[i] names row and column i; callers with
+// keys like "cat" and "dog" should display it alongside the image.
+func SaveConfusionMatrixImage(model Predictor, sessions []blueprint.TrainingSession, path string) ([]string, error) {
+	classes, matrix := ConfusionMatrix(model, sessions)
+	if len(classes) == 0 {
+		return nil, fmt.Errorf("no sessions to build a confusion matrix from")
+	}
+
+	// Mixing class indices and positions would make the labels ambiguous, so use one scheme for all keys
+	useClassIndex := true
+	for _, key := range classes {
+		if classIndex(key) < 0 {
+			useClassIndex = false
+			break
+		}
+	}
+
+	maxCount := 0
+	for _, row := range matrix {
+		for _, count := range row {
+			if count > maxCount {
+				maxCount = count
+			}
+		}
+	}
+
+	size := confusionLabelMargin + len(classes)*confusionCellSize
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for i, key := range classes {
+		label := i
+		if useClassIndex {
+			label = classIndex(key)
+		}
+		offset := confusionLabelMargin + i*confusionCellSize + confusionCellSize/4
+		drawNumber(img, label, 4, offset)
+		drawNumber(img, label, offset, 4)
+	}
+
+	for row := range matrix {
+		for col, count := range matrix[row] {
+			intensity := 0.0
+			if maxCount > 0 {
+				intensity = float64(count) / float64(maxCount)
+			}
+			// Fade from white to dark blue as the count grows
+			cellColor := color.RGBA{
+				R: uint8(255 * (1 - intensity)),
+				G: uint8(255 - 200*intensity),
+				B: uint8(255 - 100*intensity),
+				A: 255,
+			}
+			x := confusionLabelMargin + col*confusionCellSize
+			y := confusionLabelMargin + row*confusionCellSize
+			// Leave a one pixel gap so neighbouring cells stay distinguishable
+			cell := image.Rect(x, y, x+confusionCellSize-1, y+confusionCellSize-1)
+			draw.Draw(img, cell, &image.Uniform{C: cellColor}, image.Point{}, draw.Src)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create confusion matrix image: %w", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return nil, fmt.Errorf("failed to encode confusion matrix image: %w", err)
+	}
+	return classes, nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"blueprint"
+	"encoding/csv"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestExportPredictionsRowsAndHeader(t *testing.T) {
+	sessions := labelledSessions([]int{0, 1, 1}, 2)
+
+	for _, test := range []struct {
+		includeProbabilities bool
+		header               []string
+	}{
+		{false, []string{"index", "predicted", "confidence", "expected"}},
+		{true, []string{"index", "predicted", "confidence", "expected", "class_0", "class_1"}},
+	} {
+		path := filepath.Join(t.TempDir(), "predictions.csv")
+		if err := ExportPredictions(thresholdModel(), sessions, path, test.includeProbabilities); err != nil {
+			t.Fatalf("ExportPredictions(includeProbabilities=%v) error: %v", test.includeProbabilities, err)
+		}
+
+		records := readCSV(t, path)
+		if len(records) != len(sessions)+1 {
+			t.Fatalf("includeProbabilities=%v: got %d rows, want %d", test.includeProbabilities, len(records), len(sessions)+1)
+		}
+		if !reflect.DeepEqual(records[0], test.header) {
+			t.Fatalf("includeProbabilities=%v: header = %v, want %v", test.includeProbabilities, records[0], test.header)
+		}
+		for i, row := range records[1:] {
+			if len(row) != len(test.header) {
+				t.Fatalf("includeProbabilities=%v: row %d has %d columns, want %d", test.includeProbabilities, i, len(row), len(test.header))
+			}
+		}
+	}
+}
+
+// prometheusName matches a valid metric name in the text exposition format
+var prometheusName = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+func TestMetricsPrometheusParsesAsTextFormat(t *testing.T) {
+	model := blueprint.NewBlueprint(nil)
+	model.Config.Metadata.LastTrainingAccuracy = 97.5
+	model.Config.Metadata.LastTestExactErrorCount = 12
+	model.Config.Metadata.TotalLayers = 3
+
+	values := make(map[string]float64)
+	help, gauge := make(map[string]bool), make(map[string]bool)
+	for i, line := range strings.Split(strings.TrimSuffix(MetricsPrometheus(model), "\n"), "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case strings.HasPrefix(line, "# HELP "):
+			if len(fields) < 4 || !prometheusName.MatchString(fields[2]) {
+				t.Fatalf("line %d: malformed HELP line %q", i, line)
+			}
+			help[fields[2]] = true
+		case strings.HasPrefix(line, "# TYPE "):
+			if len(fields) != 4 || fields[3] != "gauge" || !help[fields[2]] {
+				t.Fatalf("line %d: malformed TYPE line %q", i, line)
+			}
+			gauge[fields[2]] = true
+		default:
+			if len(fields) != 2 || !prometheusName.MatchString(fields[0]) || !gauge[fields[0]] {
+				t.Fatalf("line %d: malformed sample line %q", i, line)
+			}
+			value, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				t.Fatalf("line %d: sample value %q: %v", i, fields[1], err)
+			}
+			values[fields[0]] = value
+		}
+	}
+
+	want := map[string]float64{
+		"layerforge_training_accuracy_percent": 97.5,
+		"layerforge_test_exact_errors":         12,
+		"layerforge_total_layers":              3,
+	}
+	for _, name := range []string{
+		"layerforge_test_accuracy_percent",
+		"layerforge_test_generous_accuracy_percent",
+		"layerforge_test_forgiveness_accuracy_percent",
+		"layerforge_training_exact_errors",
+		"layerforge_training_average_generous_error",
+		"layerforge_test_average_generous_error",
+		"layerforge_training_forgiveness_errors",
+		"layerforge_test_forgiveness_errors",
+		"layerforge_total_neurons",
+	} {
+		want[name] = 0
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Fatalf("MetricsPrometheus() samples = %v, want %v", values, want)
+	}
+}
+
+// decodePNGSize returns the width and height of a PNG file
+func decodePNGSize(t *testing.T, path string) (int, int) {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("failed to decode %s: %v", path, err)
+	}
+	return img.Bounds().Dx(), img.Bounds().Dy()
+}
+
+func TestSaveConfusionMatrixImageSizeAndLegend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "confusion.png")
+	legend, err := SaveConfusionMatrixImage(thresholdModel(), labelledSessions([]int{0, 1, 2, 1}, 3), path)
+	if err != nil {
+		t.Fatalf("SaveConfusionMatrixImage() error: %v", err)
+	}
+	if want := []string{"class_0", "class_1", "class_2"}; !reflect.DeepEqual(legend, want) {
+		t.Fatalf("legend = %v, want %v", legend, want)
+	}
+	size := confusionLabelMargin + len(legend)*confusionCellSize
+	if width, height := decodePNGSize(t, path); width != size || height != size {
+		t.Fatalf("image is %dx%d, want %dx%d", width, height, size, size)
+	}
+}
+
+func TestSaveConfusionMatrixImageLegendForNamedClasses(t *testing.T) {
+	model := &fakeModel{predict: func(input []float64) map[string]float64 {
+		return map[string]float64{"cat": 0.6, "dog": 0.4}
+	}}
+	sessions := []blueprint.TrainingSession{
+		{
+			InputVariables: map[string]interface{}{"input": []float64{0}},
+			ExpectedOutput: map[string]interface{}{"cat": 0.0, "dog": 1.0},
+		},
+		{
+			InputVariables: map[string]interface{}{"input": []float64{1}},
+			ExpectedOutput: map[string]interface{}{"cat": 1.0, "dog": 0.0},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "confusion.png")
+	legend, err := SaveConfusionMatrixImage(model, sessions, path)
+	if err != nil {
+		t.Fatalf("SaveConfusionMatrixImage() error: %v", err)
+	}
+	if want := []string{"cat", "dog"}; !reflect.DeepEqual(legend, want) {
+		t.Fatalf("legend = %v, want %v", legend, want)
+	}
+	size := confusionLabelMargin + 2*confusionCellSize
+	if width, height := decodePNGSize(t, path); width != size || height != size {
+		t.Fatalf("image is %dx%d, want %dx%d", width, height, size, size)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+)
+
+// LoadImageAsInput decodes a PNG or JPEG file, resizes it to width x height (nearest neighbour) and
+// returns an input map ready for Feedforward. Pixel values are normalized to [0, 1] like createTrainingSession.
+// Grayscale images yield width*height values; colour images yield width*height*3 values in RGB order.
+func LoadImageAsInput(path string, width, height int, grayscale bool) (map[string]interface{}, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid target size %dx%d", width, height)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image %s: %w", path, err)
+	}
+
+	bounds := img.Bounds()
+	channels := 3
+	if grayscale {
+		channels = 1
+	}
+	imageData := make([]float64, 0, width*height*channels)
+
+	for y := 0; y < height; y++ {
+		srcY := bounds.Min.Y + y*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			srcX := bounds.Min.X + x*bounds.Dx()/width
+
+			// RGBA returns 16-bit channels, scale them back to 0-255 before normalizing
+			r, g, b, _ := img.At(srcX, srcY).RGBA()
+			red, green, blue := float64(r>>8), float64(g>>8), float64(b>>8)
+
+			if grayscale {
+				luminance := 0.299*red + 0.587*green + 0.114*blue
+				imageData = append(imageData, luminance/255.0)
+			} else {
+				imageData = append(imageData, red/255.0, green/255.0, blue/255.0)
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"input": imageData,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestPNG saves a small image with a white left half and a black right half
+func writeTestPNG(t *testing.T, width, height int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "digit.png")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadImageAsInputGrayscale(t *testing.T) {
+	path := writeTestPNG(t, 56, 56)
+
+	inputVariables, err := LoadImageAsInput(path, 28, 28, true)
+	if err != nil {
+		t.Fatalf("LoadImageAsInput() error = %v", err)
+	}
+	input, ok := inputVariables["input"].([]float64)
+	if !ok {
+		t.Fatalf("input has type %T, want []float64", inputVariables["input"])
+	}
+	if len(input) != 28*28 {
+		t.Fatalf("input length = %d, want %d", len(input), 28*28)
+	}
+	if math.Abs(input[0]-1) > 1e-9 || input[27] != 0 {
+		t.Fatalf("unexpected pixel values: first %v, last in row %v", input[0], input[27])
+	}
+}
+
+func TestLoadImageAsInputColour(t *testing.T) {
+	path := writeTestPNG(t, 10, 10)
+
+	inputVariables, err := LoadImageAsInput(path, 5, 4, false)
+	if err != nil {
+		t.Fatalf("LoadImageAsInput() error = %v", err)
+	}
+	if got := len(inputVariables["input"].([]float64)); got != 5*4*3 {
+		t.Fatalf("input length = %d, want %d", got, 5*4*3)
+	}
+}
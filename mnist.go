@@ -289,37 +289,33 @@ func createTrainingSession(index int) blueprint.TrainingSession {
 }
 
 func evaluateModelPerformance() {
-	fmt.Println("Evaluating model performance on the training set...")
-	// Capture all six values for training set evaluation
-	trainingExactAccuracy, trainingGenerousAccuracy, trainingForgivenessAccuracy,
-		trainingExactErrorCount, trainingAverageGenerousError, trainingForgivenessErrorCount := bp.EvaluateModelPerformance(TrainingSessions)
+	fmt.Println("Evaluating model performance on the training and testing sets...")
+	report := FullReport(bp, TrainingSessions, TestingSessions)
+	training, testing := report.Training, report.Testing
 
-	fmt.Printf("Training set exact accuracy: %.2f%%, Exact errors: %.0f\n", trainingExactAccuracy, trainingExactErrorCount)
-	fmt.Printf("Training set generous accuracy: %.2f%%, Average generous error: %.2f\n", trainingGenerousAccuracy, trainingAverageGenerousError)
-	fmt.Printf("Training set forgiveness accuracy: %.2f%%, Forgiveness errors: %.0f\n\n", trainingForgivenessAccuracy, trainingForgivenessErrorCount)
+	fmt.Printf("Training set exact accuracy: %.2f%%, Exact errors: %.0f\n", training.ExactAccuracy, training.ExactErrorCount)
+	fmt.Printf("Training set generous accuracy: %.2f%%, Average generous error: %.2f\n", training.GenerousAccuracy, training.AverageGenerousError)
+	fmt.Printf("Training set forgiveness accuracy: %.2f%%, Forgiveness errors: %.0f\n", training.ForgivenessAccuracy, training.ForgivenessErrorCount)
+	fmt.Printf("Training set chance level: %.2f%%, Exact accuracy above chance: %.2f points\n\n", training.ChanceLevel, training.AboveChance)
 
-	fmt.Println("Evaluating model performance on the testing set...")
-	// Capture all six values for testing set evaluation
-	testingExactAccuracy, testingGenerousAccuracy, testingForgivenessAccuracy,
-		testingExactErrorCount, testingAverageGenerousError, testingForgivenessErrorCount := bp.EvaluateModelPerformance(TestingSessions)
-
-	fmt.Printf("Testing set exact accuracy: %.2f%%, Exact errors: %.0f\n", testingExactAccuracy, testingExactErrorCount)
-	fmt.Printf("Testing set generous accuracy: %.2f%%, Average generous error: %.2f\n", testingGenerousAccuracy, testingAverageGenerousError)
-	fmt.Printf("Testing set forgiveness accuracy: %.2f%%, Forgiveness errors: %.0f\n\n", testingForgivenessAccuracy, testingForgivenessErrorCount)
+	fmt.Printf("Testing set exact accuracy: %.2f%%, Exact errors: %.0f\n", testing.ExactAccuracy, testing.ExactErrorCount)
+	fmt.Printf("Testing set generous accuracy: %.2f%%, Average generous error: %.2f\n", testing.GenerousAccuracy, testing.AverageGenerousError)
+	fmt.Printf("Testing set forgiveness accuracy: %.2f%%, Forgiveness errors: %.0f\n", testing.ForgivenessAccuracy, testing.ForgivenessErrorCount)
+	fmt.Printf("Testing set chance level: %.2f%%, Exact accuracy above chance: %.2f points\n\n", testing.ChanceLevel, testing.AboveChance)
 
 	// Update model metadata with accuracy and error metrics
-	bp.Config.Metadata.LastTrainingAccuracy = trainingExactAccuracy
-	bp.Config.Metadata.LastTestAccuracy = testingExactAccuracy
-	bp.Config.Metadata.LastTestAccuracyGenerous = testingGenerousAccuracy
-	bp.Config.Metadata.LastTestAccuracyForgiveness = testingForgivenessAccuracy
+	bp.Config.Metadata.LastTrainingAccuracy = training.ExactAccuracy
+	bp.Config.Metadata.LastTestAccuracy = testing.ExactAccuracy
+	bp.Config.Metadata.LastTestAccuracyGenerous = testing.GenerousAccuracy
+	bp.Config.Metadata.LastTestAccuracyForgiveness = testing.ForgivenessAccuracy
 
 	// Update model metadata with error metrics
-	bp.Config.Metadata.LastTrainingExactErrorCount = trainingExactErrorCount
-	bp.Config.Metadata.LastTestExactErrorCount = testingExactErrorCount
-	bp.Config.Metadata.LastTrainingAverageGenerousError = trainingAverageGenerousError
-	bp.Config.Metadata.LastTestAverageGenerousError = testingAverageGenerousError
-	bp.Config.Metadata.LastTrainingForgivenessErrorCount = trainingForgivenessErrorCount
-	bp.Config.Metadata.LastTestForgivenessErrorCount = testingForgivenessErrorCount
+	bp.Config.Metadata.LastTrainingExactErrorCount = training.ExactErrorCount
+	bp.Config.Metadata.LastTestExactErrorCount = testing.ExactErrorCount
+	bp.Config.Metadata.LastTrainingAverageGenerousError = training.AverageGenerousError
+	bp.Config.Metadata.LastTestAverageGenerousError = testing.AverageGenerousError
+	bp.Config.Metadata.LastTrainingForgivenessErrorCount = training.ForgivenessErrorCount
+	bp.Config.Metadata.LastTestForgivenessErrorCount = testing.ForgivenessErrorCount
 
 	// Save training and testing sessions to metadata
 	bp.Config.Metadata.TrainingSessions = TrainingSessions
@@ -365,6 +361,12 @@ func testFeedforwardOutputVariability() {
 	} else {
 		fmt.Println("Outputs are different; feedforward process appears to be working correctly.")
 	}
+
+	// Check every output neuron across the whole training set
+	variability := OutputVariability(bp, TrainingSessions)
+	if len(variability.Constant) > 0 {
+		fmt.Printf("Warning: outputs never change across the training set: %v\n", variability.Constant)
+	}
 }
 
 // compareOutputs checks if two output maps are the same
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"blueprint"
+	"math"
+	"sort"
+	"time"
+)
+
+// profileWarmupRuns is the number of untimed forward passes made before measuring
+const profileWarmupRuns = 10
+
+// ProfileReport holds inference throughput and latency percentiles measured over a dataset
+type ProfileReport struct {
+	Throughput float64 // Inferences per second
+	P50        time.Duration
+	P95        time.Duration
+	P99        time.Duration
+}
+
+// Profile times one Feedforward call per session after a short warmup and reports throughput
+// together with the p50, p95 and p99 latencies
+func Profile(model Predictor, sessions []blueprint.TrainingSession) ProfileReport {
+	if len(sessions) == 0 {
+		return ProfileReport{}
+	}
+
+	for i := 0; i < profileWarmupRuns; i++ {
+		model.Feedforward(sessions[i%len(sessions)].InputVariables)
+	}
+
+	latencies := make([]time.Duration, len(sessions))
+	var total time.Duration
+	for i, session := range sessions {
+		start := time.Now()
+		model.Feedforward(session.InputVariables)
+		latencies[i] = time.Since(start)
+		total += latencies[i]
+	}
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	// percentile uses the nearest-rank method on the sorted latencies
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p*float64(len(latencies)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		if rank >= len(latencies) {
+			rank = len(latencies) - 1
+		}
+		return latencies[rank]
+	}
+
+	report := ProfileReport{
+		P50: percentile(0.50),
+		P95: percentile(0.95),
+		P99: percentile(0.99),
+	}
+	if total > 0 {
+		report.Throughput = float64(len(sessions)) / total.Seconds()
+	}
+	return report
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProfileReportIsOrdered(t *testing.T) {
+	// Sleeping keeps every measured latency above the clock resolution
+	model := &fakeModel{predict: func(input []float64) map[string]float64 {
+		time.Sleep(time.Microsecond)
+		return map[string]float64{"class_0": 1}
+	}}
+
+	report := Profile(model, indexedSessions(100))
+	if report.P50 <= 0 || report.P50 > report.P95 || report.P95 > report.P99 {
+		t.Fatalf("Profile() percentiles p50=%v p95=%v p99=%v, want 0 < p50 <= p95 <= p99", report.P50, report.P95, report.P99)
+	}
+	if report.Throughput <= 0 {
+		t.Fatalf("Profile() throughput = %v, want > 0", report.Throughput)
+	}
+}
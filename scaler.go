@@ -0,0 +1,110 @@
+package main
+
+import (
+	"blueprint"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// InputScaler standardizes raw input vectors with the per-feature mean and standard deviation
+// computed from a training set, so prediction applies exactly the same transform as training
+type InputScaler struct {
+	Mean []float64 `json:"mean"`
+	Std  []float64 `json:"std"`
+}
+
+// FitInputScaler computes the per-feature mean and standard deviation of the sessions' input vectors.
+// Features with zero variance get a standard deviation of 1 so they are only centred.
+func FitInputScaler(sessions []blueprint.TrainingSession) (*InputScaler, error) {
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("cannot fit input scaler on an empty dataset")
+	}
+
+	size := len(sessionInput(sessions[0]))
+	if size == 0 {
+		return nil, fmt.Errorf("session 0 has no []float64 input vector under \"input\"")
+	}
+	scaler := &InputScaler{
+		Mean: make([]float64, size),
+		Std:  make([]float64, size),
+	}
+
+	for i, session := range sessions {
+		input := sessionInput(session)
+		if len(input) != size {
+			return nil, fmt.Errorf("session %d has %d inputs, expected %d", i, len(input), size)
+		}
+		for j, value := range input {
+			scaler.Mean[j] += value
+		}
+	}
+	for j := range scaler.Mean {
+		scaler.Mean[j] /= float64(len(sessions))
+	}
+
+	for _, session := range sessions {
+		for j, value := range sessionInput(session) {
+			diff := value - scaler.Mean[j]
+			scaler.Std[j] += diff * diff
+		}
+	}
+	for j := range scaler.Std {
+		scaler.Std[j] = math.Sqrt(scaler.Std[j] / float64(len(sessions)))
+		if scaler.Std[j] == 0 {
+			scaler.Std[j] = 1
+		}
+	}
+	return scaler, nil
+}
+
+// Transform returns a standardized copy of a raw input vector. Values beyond the fitted features are
+// copied unscaled; use PredictRaw to reject vectors of the wrong length.
+func (s *InputScaler) Transform(rawInput []float64) []float64 {
+	scaled := make([]float64, len(rawInput))
+	for i, value := range rawInput {
+		if i < len(s.Mean) {
+			scaled[i] = (value - s.Mean[i]) / s.Std[i]
+		} else {
+			scaled[i] = value
+		}
+	}
+	return scaled
+}
+
+// PredictRaw scales a raw input vector with the fitted scaler and feeds it forward.
+// It returns an error when the vector does not have one value per scaler feature.
+func PredictRaw(model Predictor, scaler *InputScaler, rawInput []float64) (map[string]float64, error) {
+	if len(rawInput) != len(scaler.Mean) {
+		return nil, fmt.Errorf("raw input has %d values, scaler expects %d", len(rawInput), len(scaler.Mean))
+	}
+	return model.Feedforward(map[string]interface{}{
+		"input": scaler.Transform(rawInput),
+	}), nil
+}
+
+// SaveInputScaler writes the scaler to a JSON file that can be kept next to the saved model
+func SaveInputScaler(scaler *InputScaler, path string) error {
+	data, err := json.MarshalIndent(scaler, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode input scaler: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadInputScaler reads a scaler previously written by SaveInputScaler
+func LoadInputScaler(path string) (*InputScaler, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input scaler: %w", err)
+	}
+	var scaler InputScaler
+	if err := json.Unmarshal(data, &scaler); err != nil {
+		return nil, fmt.Errorf("failed to decode input scaler: %w", err)
+	}
+	if len(scaler.Mean) != len(scaler.Std) {
+		return nil, fmt.Errorf("input scaler has %d means but %d standard deviations", len(scaler.Mean), len(scaler.Std))
+	}
+	return &scaler, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"blueprint"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestFitInputScalerStandardizesInputs(t *testing.T) {
+	sessions := []blueprint.TrainingSession{
+		{InputVariables: map[string]interface{}{"input": []float64{0, 10}}},
+		{InputVariables: map[string]interface{}{"input": []float64{255, 10}}},
+	}
+
+	scaler, err := FitInputScaler(sessions)
+	if err != nil {
+		t.Fatalf("FitInputScaler() error = %v", err)
+	}
+	scaled := scaler.Transform([]float64{255, 10})
+	// The constant second feature is only centred
+	if math.Abs(scaled[0]-1) > 1e-9 || scaled[1] != 0 {
+		t.Fatalf("Transform() = %v, want [1 0]", scaled)
+	}
+}
+
+func TestFitInputScalerRejectsMissingInput(t *testing.T) {
+	sessions := []blueprint.TrainingSession{
+		{InputVariables: map[string]interface{}{"pixels": []float64{1, 2}}},
+	}
+	if _, err := FitInputScaler(sessions); err == nil {
+		t.Fatal("FitInputScaler() succeeded on a session without an input vector")
+	}
+}
+
+func TestPredictRawMatchesFeedforwardOnScaledInput(t *testing.T) {
+	scaler := &InputScaler{Mean: []float64{10, 0}, Std: []float64{2, 4}}
+	// The model echoes its input so any difference in scaling shows up in the output
+	model := &fakeModel{predict: func(input []float64) map[string]float64 {
+		return map[string]float64{"class_0": input[0], "class_1": input[1]}
+	}}
+
+	got, err := PredictRaw(model, scaler, []float64{14, -8})
+	if err != nil {
+		t.Fatalf("PredictRaw() error = %v", err)
+	}
+	want := model.Feedforward(map[string]interface{}{"input": []float64{2, -2}})
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("PredictRaw() = %v, want %v", got, want)
+	}
+}
+
+func TestPredictRawRejectsLengthMismatch(t *testing.T) {
+	scaler := &InputScaler{Mean: []float64{0, 0}, Std: []float64{1, 1}}
+	for _, rawInput := range [][]float64{{1}, {1, 2, 3}} {
+		if _, err := PredictRaw(thresholdModel(), scaler, rawInput); err == nil {
+			t.Fatalf("PredictRaw(%v) succeeded with a 2-feature scaler", rawInput)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import "math"
+
+// ReduceLROnPlateau lowers the learning rate when the validation loss stops improving.
+// The training loop calls Step once per epoch with the latest validation loss and uses the returned rate.
+type ReduceLROnPlateau struct {
+	LearningRate float64
+	Factor       float64 // Multiplier applied to the rate after a plateau, e.g. 0.5
+	Patience     int     // Epochs without improvement before the rate is reduced
+	MinLR        float64 // The rate is never reduced below this value
+
+	bestLoss  float64
+	badEpochs int
+}
+
+// NewReduceLROnPlateau creates a plateau scheduler starting at the given learning rate
+func NewReduceLROnPlateau(learningRate, factor float64, patience int, minLR float64) *ReduceLROnPlateau {
+	return &ReduceLROnPlateau{
+		LearningRate: learningRate,
+		Factor:       factor,
+		Patience:     patience,
+		MinLR:        minLR,
+		bestLoss:     math.Inf(1),
+	}
+}
+
+// Step records the validation loss for an epoch and returns the learning rate to use next
+func (s *ReduceLROnPlateau) Step(validationLoss float64) float64 {
+	if validationLoss < s.bestLoss {
+		s.bestLoss = validationLoss
+		s.badEpochs = 0
+		return s.LearningRate
+	}
+
+	s.badEpochs++
+	if s.badEpochs >= s.Patience {
+		s.LearningRate = math.Max(s.LearningRate*s.Factor, s.MinLR)
+		s.badEpochs = 0
+	}
+	return s.LearningRate
+}
+
+// CosineAnnealing follows a half cosine from BaseLR down to EtaMin over TMax steps (or epochs).
+// With Restart set the schedule jumps back to BaseLR after each cycle (SGDR warm restarts).
+type CosineAnnealing struct {
+	BaseLR  float64
+	EtaMin  float64
+	TMax    int
+	Restart bool
+}
+
+// NewCosineAnnealing creates a schedule that anneals once and then stays at etaMin
+func NewCosineAnnealing(baseLR float64, tMax int, etaMin float64) *CosineAnnealing {
+	return &CosineAnnealing{BaseLR: baseLR, EtaMin: etaMin, TMax: tMax}
+}
+
+// NewCosineAnnealingWarmRestarts creates a schedule that restarts the cosine cycle every tMax+1 steps
+func NewCosineAnnealingWarmRestarts(baseLR float64, tMax int, etaMin float64) *CosineAnnealing {
+	return &CosineAnnealing{BaseLR: baseLR, EtaMin: etaMin, TMax: tMax, Restart: true}
+}
+
+// LearningRate returns the rate for a step. Each cycle starts at BaseLR on step 0 and reaches EtaMin on step TMax.
+func (s *CosineAnnealing) LearningRate(step int) float64 {
+	if s.TMax <= 0 {
+		return s.EtaMin
+	}
+
+	position := step
+	if s.Restart {
+		position = step % (s.TMax + 1)
+	} else if position > s.TMax {
+		position = s.TMax
+	}
+	if position < 0 {
+		position = 0
+	}
+
+	progress := float64(position) / float64(s.TMax)
+	return s.EtaMin + (s.BaseLR-s.EtaMin)*(1+math.Cos(math.Pi*progress))/2
+}
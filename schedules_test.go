@@ -0,0 +1,71 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestReduceLROnPlateauDropsAfterPatience(t *testing.T) {
+	scheduler := NewReduceLROnPlateau(0.1, 0.5, 3, 0.02)
+
+	// The first loss is an improvement, the next ones are flat
+	rates := []float64{}
+	for epoch := 0; epoch < 10; epoch++ {
+		rates = append(rates, scheduler.Step(1.0))
+	}
+
+	want := []float64{0.1, 0.1, 0.1, 0.05, 0.05, 0.05, 0.025, 0.025, 0.025, 0.02}
+	for epoch, rate := range rates {
+		if math.Abs(rate-want[epoch]) > 1e-12 {
+			t.Fatalf("epoch %d: learning rate = %v, want %v (all rates %v)", epoch, rate, want[epoch], rates)
+		}
+	}
+}
+
+func TestReduceLROnPlateauKeepsRateWhileImproving(t *testing.T) {
+	scheduler := NewReduceLROnPlateau(0.1, 0.5, 2, 0.001)
+	for epoch, loss := range []float64{1.0, 0.9, 0.8, 0.7, 0.6} {
+		if rate := scheduler.Step(loss); rate != 0.1 {
+			t.Fatalf("epoch %d: learning rate = %v, want 0.1", epoch, rate)
+		}
+	}
+}
+
+func TestCosineAnnealingFollowsCosineCurve(t *testing.T) {
+	schedule := NewCosineAnnealing(0.1, 10, 0.001)
+
+	if rate := schedule.LearningRate(0); math.Abs(rate-0.1) > 1e-12 {
+		t.Fatalf("step 0: learning rate = %v, want 0.1", rate)
+	}
+	if rate := schedule.LearningRate(5); math.Abs(rate-0.0505) > 1e-12 {
+		t.Fatalf("step 5: learning rate = %v, want the midpoint 0.0505", rate)
+	}
+	if rate := schedule.LearningRate(10); math.Abs(rate-0.001) > 1e-12 {
+		t.Fatalf("step 10: learning rate = %v, want etaMin 0.001", rate)
+	}
+	if rate := schedule.LearningRate(25); math.Abs(rate-0.001) > 1e-12 {
+		t.Fatalf("step 25: learning rate = %v, want to stay at etaMin", rate)
+	}
+
+	previous := schedule.LearningRate(0)
+	for step := 1; step <= 10; step++ {
+		rate := schedule.LearningRate(step)
+		if rate > previous {
+			t.Fatalf("step %d: learning rate rose from %v to %v", step, previous, rate)
+		}
+		previous = rate
+	}
+}
+
+func TestCosineAnnealingWarmRestarts(t *testing.T) {
+	schedule := NewCosineAnnealingWarmRestarts(0.1, 4, 0.0)
+
+	for _, cycleStart := range []int{0, 5, 10} {
+		if rate := schedule.LearningRate(cycleStart); math.Abs(rate-0.1) > 1e-12 {
+			t.Fatalf("step %d: learning rate = %v, want a restart at 0.1", cycleStart, rate)
+		}
+		if rate := schedule.LearningRate(cycleStart + 4); math.Abs(rate) > 1e-12 {
+			t.Fatalf("step %d: learning rate = %v, want etaMin at the cycle end", cycleStart+4, rate)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"blueprint"
+	"bufio"
+	"encoding/csv"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ShuffleStream wraps a session iterator with a fixed-size shuffle buffer.
+// At most bufferSize sessions are held in memory; each call emits a random session from the buffer
+// and refills its slot from the source, so the output is an approximate shuffle of the input.
+func ShuffleStream(next func() (blueprint.TrainingSession, bool), bufferSize int, seed int64) func() (blueprint.TrainingSession, bool) {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	rng := rand.New(rand.NewSource(seed))
+	buffer := make([]blueprint.TrainingSession, 0, bufferSize)
+	exhausted := false
+
+	return func() (blueprint.TrainingSession, bool) {
+		// Top up the buffer until it is full or the source runs dry
+		for !exhausted && len(buffer) < bufferSize {
+			session, ok := next()
+			if !ok {
+				exhausted = true
+				break
+			}
+			buffer = append(buffer, session)
+		}
+
+		if len(buffer) == 0 {
+			return blueprint.TrainingSession{}, false
+		}
+
+		index := rng.Intn(len(buffer))
+		session := buffer[index]
+		last := len(buffer) - 1
+		buffer[index] = buffer[last]
+		buffer[last] = blueprint.TrainingSession{}
+		buffer = buffer[:last]
+		return session, true
+	}
+}
+
+// WeightedSample draws count sessions with replacement, each with probability proportional to its weight.
+// Weights must be non-negative, one per session, with a positive total.
+func WeightedSample(sessions []blueprint.TrainingSession, weights []float64, count int, rng *rand.Rand) ([]blueprint.TrainingSession, error) {
+	if len(weights) != len(sessions) {
+		return nil, fmt.Errorf("got %d weights for %d sessions", len(weights), len(sessions))
+	}
+
+	cumulative := make([]float64, len(weights))
+	total := 0.0
+	for i, weight := range weights {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("weight %d is %v, want a finite non-negative value", i, weight)
+		}
+		total += weight
+		cumulative[i] = total
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("weights must have a positive total")
+	}
+
+	sample := make([]blueprint.TrainingSession, count)
+	for i := range sample {
+		// The first cumulative weight above the draw picks the session, so zero weights are never chosen
+		target := rng.Float64() * total
+		index := sort.Search(len(cumulative), func(j int) bool {
+			return cumulative[j] > target
+		})
+		sample[i] = sessions[index]
+	}
+	return sample, nil
+}
+
+// TrainWithSampler oversamples by weight instead of reweighting the loss. Each epoch draws len(sessions)
+// sessions with WeightedSample and passes them to trainFn, which runs one training pass over them.
+func TrainWithSampler(sessions []blueprint.TrainingSession, weights []float64, epochs int, seed int64, trainFn func([]blueprint.TrainingSession)) error {
+	rng := rand.New(rand.NewSource(seed))
+	for epoch := 0; epoch < epochs; epoch++ {
+		sample, err := WeightedSample(sessions, weights, len(sessions), rng)
+		if err != nil {
+			return fmt.Errorf("failed to sample epoch %d: %w", epoch, err)
+		}
+		trainFn(sample)
+	}
+	return nil
+}
+
+// sessionInput returns the flattened input vector stored under the "input" key of a session
+func sessionInput(session blueprint.TrainingSession) []float64 {
+	input, _ := session.InputVariables["input"].([]float64)
+	return input
+}
+
+// FindDuplicateSessions returns index pairs (i into a, j into b) whose input vectors are within
+// tolerance of each other by L2 distance. Use it to catch leakage between training and testing splits.
+// Sessions without a []float64 vector under "input" cannot be compared and are skipped.
+// A negative tolerance matches nothing and returns nil.
+func FindDuplicateSessions(a, b []blueprint.TrainingSession, tolerance float64) [][2]int {
+	if tolerance < 0 {
+		return nil
+	}
+
+	var duplicates [][2]int
+	limit := tolerance * tolerance
+
+	inputsB := make([][]float64, len(b))
+	for j, sessionB := range b {
+		inputsB[j] = sessionInput(sessionB)
+	}
+
+	for i, sessionA := range a {
+		inputA := sessionInput(sessionA)
+		if len(inputA) == 0 {
+			continue
+		}
+		for j, inputB := range inputsB {
+			if len(inputA) != len(inputB) {
+				continue
+			}
+
+			// Compare squared distances and stop as soon as the limit is exceeded
+			distance := 0.0
+			for k := range inputA {
+				diff := inputA[k] - inputB[k]
+				distance += diff * diff
+				if distance > limit {
+					break
+				}
+			}
+			if distance <= limit {
+				duplicates = append(duplicates, [2]int{i, j})
+			}
+		}
+	}
+	return duplicates
+}
+
+// mixHash applies the splitmix64 finalizer. Raw FNV-1a leaves the high bits poorly mixed for
+// short keys such as small integers, which would skew the split away from the requested fraction.
+func mixHash(hash uint64) uint64 {
+	hash ^= hash >> 30
+	hash *= 0xbf58476d1ce4e5b9
+	hash ^= hash >> 27
+	hash *= 0x94d049bb133111eb
+	hash ^= hash >> 31
+	return hash
+}
+
+// HashSplit assigns each session to the training or testing split by hashing a stable key,
+// so an example always lands in the same split no matter how the dataset is ordered
+func HashSplit(sessions []blueprint.TrainingSession, testFraction float64, keyFn func(blueprint.TrainingSession) string) (train, test []blueprint.TrainingSession) {
+	for _, session := range sessions {
+		hasher := fnv.New64a()
+		hasher.Write([]byte(keyFn(session)))
+
+		// Use the top 53 bits of the mixed hash as a uniform position in [0, 1)
+		position := float64(mixHash(hasher.Sum64())>>11) / (1 << 53)
+		if position < testFraction {
+			test = append(test, session)
+		} else {
+			train = append(train, session)
+		}
+	}
+	return train, test
+}
+
+// cachedSession is the on-disk form of a TrainingSession used by the binary cache.
+// SavedLayerStates are not cached since they are rebuilt during training.
+type cachedSession struct {
+	InputVariables map[string]interface{}
+	ExpectedOutput map[string]interface{}
+	Learned        bool
+}
+
+// SaveSessionsBinary writes sessions to a gob-encoded cache file, which loads much faster than the JSON data file
+func SaveSessionsBinary(sessions []blueprint.TrainingSession, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create session cache: %w", err)
+	}
+	defer file.Close()
+
+	cached := make([]cachedSession, len(sessions))
+	for i, session := range sessions {
+		cached[i] = cachedSession{
+			InputVariables: session.InputVariables,
+			ExpectedOutput: session.ExpectedOutput,
+			Learned:        session.Learned,
+		}
+	}
+
+	writer := bufio.NewWriter(file)
+	if err := gob.NewEncoder(writer).Encode(cached); err != nil {
+		return fmt.Errorf("failed to encode sessions: %w", err)
+	}
+	return writer.Flush()
+}
+
+// LoadSessionsBinary reads sessions from a cache file written by SaveSessionsBinary
+func LoadSessionsBinary(path string) ([]blueprint.TrainingSession, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session cache: %w", err)
+	}
+	defer file.Close()
+
+	var cached []cachedSession
+	if err := gob.NewDecoder(bufio.NewReader(file)).Decode(&cached); err != nil {
+		return nil, fmt.Errorf("failed to decode sessions: %w", err)
+	}
+
+	sessions := make([]blueprint.TrainingSession, len(cached))
+	for i, session := range cached {
+		sessions[i] = blueprint.TrainingSession{
+			InputVariables:   session.InputVariables,
+			SavedLayerStates: []blueprint.LayerState{},
+			ExpectedOutput:   session.ExpectedOutput,
+			Learned:          session.Learned,
+		}
+	}
+	return sessions, nil
+}
+
+// ShardIndexEntry describes one shard file written by ExportSessionsShards
+type ShardIndexEntry struct {
+	File   string `json:"file"`
+	Offset int    `json:"offset"` // Position of the shard's first session in the original slice
+	Count  int    `json:"count"`
+}
+
+// ShardIndex is the content of the index.json file written next to the shards
+type ShardIndex struct {
+	TotalSessions int               `json:"total_sessions"`
+	ShardSize     int               `json:"shard_size"`
+	Shards        []ShardIndexEntry `json:"shards"`
+}
+
+// ExportSessionsShards splits sessions into files of at most shardSize sessions so they can be loaded in parallel.
+// Each shard-NNNNN.bin file uses the same gob encoding as SaveSessionsBinary and can be read with LoadSessionsBinary.
+// An index.json file lists every shard with its offset and session count.
+func ExportSessionsShards(sessions []blueprint.TrainingSession, dir string, shardSize int) error {
+	if shardSize <= 0 {
+		return fmt.Errorf("shard size must be positive, got %d", shardSize)
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create shard directory: %w", err)
+	}
+
+	index := ShardIndex{
+		TotalSessions: len(sessions),
+		ShardSize:     shardSize,
+	}
+	for offset := 0; offset < len(sessions); offset += shardSize {
+		end := offset + shardSize
+		if end > len(sessions) {
+			end = len(sessions)
+		}
+
+		fileName := fmt.Sprintf("shard-%05d.bin", len(index.Shards))
+		if err := SaveSessionsBinary(sessions[offset:end], filepath.Join(dir, fileName)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", fileName, err)
+		}
+		index.Shards = append(index.Shards, ShardIndexEntry{
+			File:   fileName,
+			Offset: offset,
+			Count:  end - offset,
+		})
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode shard index: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, "index.json"), data, 0644)
+}
+
+// LoadOneHotCSV reads a CSV whose labels are already one-hot encoded across labelColumns.
+// The remaining columns are returned as features, in file order. A row with no label column set is an error,
+// and so is a column listed more than once in labelColumns.
+func LoadOneHotCSV(path string, labelColumns []int, hasHeader bool) ([][]float64, [][]float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open CSV: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if hasHeader && len(records) > 0 {
+		records = records[1:]
+	}
+
+	isLabel := make(map[int]bool, len(labelColumns))
+	for _, column := range labelColumns {
+		if isLabel[column] {
+			return nil, nil, fmt.Errorf("label column %d is listed more than once", column)
+		}
+		isLabel[column] = true
+	}
+
+	var features, targets [][]float64
+	for row, record := range records {
+		// Report line numbers as they appear in the file
+		line := row + 1
+		if hasHeader {
+			line++
+		}
+
+		for _, column := range labelColumns {
+			if column < 0 || column >= len(record) {
+				return nil, nil, fmt.Errorf("line %d: label column %d out of range for %d columns", line, column, len(record))
+			}
+		}
+
+		featureRow := make([]float64, 0, len(record)-len(labelColumns))
+		targetRow := make([]float64, len(labelColumns))
+		for column, field := range record {
+			value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d, column %d: %w", line, column, err)
+			}
+			if !isLabel[column] {
+				featureRow = append(featureRow, value)
+			}
+		}
+
+		labelSet := false
+		for i, column := range labelColumns {
+			targetRow[i], _ = strconv.ParseFloat(strings.TrimSpace(record[column]), 64)
+			if targetRow[i] != 0 {
+				labelSet = true
+			}
+		}
+		if !labelSet {
+			return nil, nil, fmt.Errorf("line %d: no label column is set", line)
+		}
+
+		features = append(features, featureRow)
+		targets = append(targets, targetRow)
+	}
+	return features, targets, nil
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"blueprint"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// indexedSessions builds sessions whose single input value is their position, for identifying them later
+func indexedSessions(count int) []blueprint.TrainingSession {
+	sessions := make([]blueprint.TrainingSession, count)
+	for i := range sessions {
+		sessions[i] = blueprint.TrainingSession{
+			InputVariables: map[string]interface{}{"input": []float64{float64(i)}},
+			ExpectedOutput: map[string]interface{}{"class_0": 1.0},
+		}
+	}
+	return sessions
+}
+
+func TestShuffleStreamIsBoundedPermutation(t *testing.T) {
+	const total, bufferSize = 100, 8
+	sessions := indexedSessions(total)
+
+	position, pulled := 0, 0
+	next := func() (blueprint.TrainingSession, bool) {
+		if position >= len(sessions) {
+			return blueprint.TrainingSession{}, false
+		}
+		position++
+		pulled++
+		return sessions[position-1], true
+	}
+
+	stream := ShuffleStream(next, bufferSize, 42)
+	var seen []int
+	inOrder := true
+	for {
+		session, ok := stream()
+		if !ok {
+			break
+		}
+		// The stream may only read ahead by the buffer size
+		if pulled-len(seen) > bufferSize {
+			t.Fatalf("stream holds %d sessions, buffer size is %d", pulled-len(seen), bufferSize)
+		}
+		value := int(sessionInput(session)[0])
+		if value != len(seen) {
+			inOrder = false
+		}
+		seen = append(seen, value)
+	}
+
+	if inOrder {
+		t.Fatal("stream returned sessions in their original order")
+	}
+	sort.Ints(seen)
+	if len(seen) != total {
+		t.Fatalf("stream returned %d sessions, want %d", len(seen), total)
+	}
+	for i, value := range seen {
+		if value != i {
+			t.Fatalf("stream output is not a permutation: sorted[%d] = %d", i, value)
+		}
+	}
+}
+
+func TestFindDuplicateSessionsFindsPlantedDuplicate(t *testing.T) {
+	a := []blueprint.TrainingSession{
+		{InputVariables: map[string]interface{}{"input": []float64{0, 0, 1}}},
+		{InputVariables: map[string]interface{}{"input": []float64{0.5, 0.5, 0.5}}},
+	}
+	b := []blueprint.TrainingSession{
+		{InputVariables: map[string]interface{}{"input": []float64{1, 1, 1}}},
+		{InputVariables: map[string]interface{}{"input": []float64{0.5, 0.5, 0.501}}},
+		{InputVariables: map[string]interface{}{"input": []float64{0, 1, 0}}},
+	}
+
+	got := FindDuplicateSessions(a, b, 0.01)
+	if len(got) != 1 || got[0] != [2]int{1, 1} {
+		t.Fatalf("FindDuplicateSessions() = %v, want [[1 1]]", got)
+	}
+}
+
+func TestFindDuplicateSessionsSkipsSessionsWithoutInput(t *testing.T) {
+	a := []blueprint.TrainingSession{
+		{InputVariables: map[string]interface{}{"pixels": []float64{1, 2}}},
+		{InputVariables: map[string]interface{}{"input": []interface{}{1.0, 2.0}}},
+	}
+	b := []blueprint.TrainingSession{
+		{InputVariables: map[string]interface{}{"pixels": []float64{3, 4}}},
+		{InputVariables: map[string]interface{}{"input": []float64{}}},
+	}
+
+	if got := FindDuplicateSessions(a, b, 0.01); len(got) != 0 {
+		t.Fatalf("FindDuplicateSessions() = %v, want no duplicates", got)
+	}
+}
+
+func TestFindDuplicateSessionsNegativeTolerance(t *testing.T) {
+	a := []blueprint.TrainingSession{{InputVariables: map[string]interface{}{"input": []float64{1, 2}}}}
+
+	// Squaring the tolerance would otherwise turn -1 into a match on identical inputs
+	if got := FindDuplicateSessions(a, a, -1); got != nil {
+		t.Fatalf("FindDuplicateSessions(tolerance=-1) = %v, want nil", got)
+	}
+}
+
+func TestTrainWithSamplerMatchesWeights(t *testing.T) {
+	sessions := indexedSessions(4)
+	weights := []float64{1, 2, 7, 0}
+
+	counts := make([]int, len(sessions))
+	draws := 0
+	err := TrainWithSampler(sessions, weights, 5000, 42, func(sample []blueprint.TrainingSession) {
+		if len(sample) != len(sessions) {
+			t.Fatalf("epoch sample has %d sessions, want %d", len(sample), len(sessions))
+		}
+		for _, session := range sample {
+			counts[int(sessionInput(session)[0])]++
+			draws++
+		}
+	})
+	if err != nil {
+		t.Fatalf("TrainWithSampler() error: %v", err)
+	}
+
+	for i, weight := range weights {
+		got := float64(counts[i]) / float64(draws)
+		want := weight / 10
+		if math.Abs(got-want) > 0.01 {
+			t.Fatalf("session %d drawn with frequency %.4f, want %.2f", i, got, want)
+		}
+	}
+}
+
+func TestWeightedSampleRejectsInvalidWeights(t *testing.T) {
+	sessions := indexedSessions(2)
+	rng := rand.New(rand.NewSource(1))
+	for _, weights := range [][]float64{{1}, {1, -1}, {0, 0}, {math.NaN(), 1}} {
+		if _, err := WeightedSample(sessions, weights, 1, rng); err == nil {
+			t.Fatalf("WeightedSample(weights=%v) returned no error", weights)
+		}
+	}
+}
+
+// sessionKey uses the single input value of an indexedSessions entry as its stable key
+func sessionKey(session blueprint.TrainingSession) string {
+	return strconv.Itoa(int(sessionInput(session)[0]))
+}
+
+func TestHashSplitHonoursFractionForShortKeys(t *testing.T) {
+	sessions := indexedSessions(1000)
+	for _, fraction := range []float64{0.1, 0.2, 0.3, 0.5, 0.8} {
+		train, test := HashSplit(sessions, fraction, sessionKey)
+		if len(train)+len(test) != len(sessions) {
+			t.Fatalf("fraction %v: split lost sessions (%d + %d)", fraction, len(train), len(test))
+		}
+		observed := float64(len(test)) / float64(len(sessions))
+		if math.Abs(observed-fraction) > 0.05 {
+			t.Errorf("fraction %v: observed test fraction %v", fraction, observed)
+		}
+	}
+}
+
+func TestHashSplitIsStableAcrossOrderings(t *testing.T) {
+	sessions := indexedSessions(500)
+	_, test := HashSplit(sessions, 0.25, sessionKey)
+	inTest := make(map[string]bool, len(test))
+	for _, session := range test {
+		inTest[sessionKey(session)] = true
+	}
+
+	shuffled := append([]blueprint.TrainingSession(nil), sessions...)
+	rand.New(rand.NewSource(7)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	train, shuffledTest := HashSplit(shuffled, 0.25, sessionKey)
+	if len(shuffledTest) != len(test) {
+		t.Fatalf("test split has %d sessions after shuffling, want %d", len(shuffledTest), len(test))
+	}
+	for _, session := range shuffledTest {
+		if !inTest[sessionKey(session)] {
+			t.Fatalf("session %s moved into the test split after shuffling", sessionKey(session))
+		}
+	}
+	for _, session := range train {
+		if inTest[sessionKey(session)] {
+			t.Fatalf("session %s moved into the training split after shuffling", sessionKey(session))
+		}
+	}
+}
+
+func TestSessionsBinaryRoundTrip(t *testing.T) {
+	sessions := []blueprint.TrainingSession{
+		{
+			InputVariables: map[string]interface{}{"input": []float64{0.1, 0.2, 0.3}},
+			ExpectedOutput: map[string]interface{}{"class_0": 0.0, "class_1": 1.0},
+			Learned:        true,
+		},
+		{
+			InputVariables: map[string]interface{}{"input": []float64{0.9, 0.8, 0.7}},
+			ExpectedOutput: map[string]interface{}{"class_0": 1.0, "class_1": 0.0},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "sessions.bin")
+	if err := SaveSessionsBinary(sessions, path); err != nil {
+		t.Fatalf("SaveSessionsBinary() error = %v", err)
+	}
+	loaded, err := LoadSessionsBinary(path)
+	if err != nil {
+		t.Fatalf("LoadSessionsBinary() error = %v", err)
+	}
+
+	if len(loaded) != len(sessions) {
+		t.Fatalf("loaded %d sessions, want %d", len(loaded), len(sessions))
+	}
+	for i := range sessions {
+		if !reflect.DeepEqual(loaded[i].InputVariables, sessions[i].InputVariables) ||
+			!reflect.DeepEqual(loaded[i].ExpectedOutput, sessions[i].ExpectedOutput) ||
+			loaded[i].Learned != sessions[i].Learned {
+			t.Fatalf("session %d changed in the round trip: got %+v, want %+v", i, loaded[i], sessions[i])
+		}
+	}
+}
+
+func TestExportSessionsShardsWritesIndex(t *testing.T) {
+	sessions := indexedSessions(23)
+	dir := t.TempDir()
+
+	if err := ExportSessionsShards(sessions, dir, 10); err != nil {
+		t.Fatalf("ExportSessionsShards() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index ShardIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("index.json is not valid: %v", err)
+	}
+	if index.TotalSessions != 23 || len(index.Shards) != 3 {
+		t.Fatalf("index = %+v, want 23 sessions in 3 shards", index)
+	}
+
+	total := 0
+	for i, shard := range index.Shards {
+		loaded, err := LoadSessionsBinary(filepath.Join(dir, shard.File))
+		if err != nil {
+			t.Fatalf("shard %d: %v", i, err)
+		}
+		if len(loaded) != shard.Count {
+			t.Fatalf("shard %d holds %d sessions, index says %d", i, len(loaded), shard.Count)
+		}
+		if shard.Offset != total || int(sessionInput(loaded[0])[0]) != shard.Offset {
+			t.Fatalf("shard %d starts at session %v, index offset %d", i, sessionInput(loaded[0])[0], shard.Offset)
+		}
+		total += len(loaded)
+	}
+	if total != len(sessions) {
+		t.Fatalf("shards hold %d sessions, want %d", total, len(sessions))
+	}
+}
+
+// writeCSV saves content to a temporary CSV file and returns its path
+func writeCSV(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadOneHotCSV(t *testing.T) {
+	path := writeCSV(t, "x1,red,green,blue,x2\n0.5,1,0,0,2\n0.25,0,0,1,4\n")
+
+	features, targets, err := LoadOneHotCSV(path, []int{1, 2, 3}, true)
+	if err != nil {
+		t.Fatalf("LoadOneHotCSV() error = %v", err)
+	}
+	wantFeatures := [][]float64{{0.5, 2}, {0.25, 4}}
+	wantTargets := [][]float64{{1, 0, 0}, {0, 0, 1}}
+	if !reflect.DeepEqual(features, wantFeatures) || !reflect.DeepEqual(targets, wantTargets) {
+		t.Fatalf("LoadOneHotCSV() = %v, %v, want %v, %v", features, targets, wantFeatures, wantTargets)
+	}
+}
+
+func TestLoadOneHotCSVErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		labelColumns []int
+	}{
+		{"no label set", "0.5,0,0,0\n", []int{1, 2, 3}},
+		{"duplicate label column", "0.5,1,0,0\n", []int{1, 1, 1}},
+		{"label column out of range", "0.5,1\n", []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := LoadOneHotCSV(writeCSV(t, tt.content), tt.labelColumns, false); err == nil {
+				t.Fatal("LoadOneHotCSV() succeeded, want an error")
+			}
+		})
+	}
+}